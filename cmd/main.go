@@ -1,36 +1,97 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"database/sql"
 	"flag"
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"go-protos/config"
 	"go-protos/internal/application"
 	"go-protos/internal/domain"
 	"go-protos/internal/infrastructure/database"
+	"go-protos/internal/infrastructure/eventbus"
+	"go-protos/internal/infrastructure/logging"
+	"go-protos/internal/infrastructure/metrics"
 	persistence "go-protos/internal/infrastructure/persistence/mariadb"
+	"go-protos/internal/infrastructure/ratelimit"
+	"go-protos/internal/infrastructure/security/auth"
+	"go-protos/internal/infrastructure/security/password"
+	"go-protos/internal/infrastructure/security/tlsconfig"
+	"go-protos/internal/infrastructure/security/token"
+	"go-protos/internal/infrastructure/telemetry"
 	"go-protos/internal/interfaces/grpc"
+	"go-protos/internal/interfaces/http"
+	"go-protos/pkg/lifecycle"
 	"go-protos/pkg/mariadb"
 )
 
 func main() {
 	// 解析命令行参数
-	var configPath string
-	flag.StringVar(&configPath, "config", "config/config.yaml", "配置文件路径")
+	opts := config.LoadOptions{Overrides: make(map[string]string)}
+	flag.StringVar(&opts.Path, "config", "config/config.yaml", "基础配置文件路径")
+	flag.StringVar(&opts.Env, "env", "", "环境名，叠加同目录下的 config-<env>.yaml，为空时读取 APP_ENV")
+	flag.Func("set", "覆盖配置项，格式 key=value，可重复指定，如 -set database.host=db", func(s string) error {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("expected key=value, got %q", s)
+		}
+		opts.Overrides[key] = value
+		return nil
+	})
 	flag.Parse()
 
 	// 加载配置
-	cfg, err := config.Load(configPath)
+	cfg, err := config.Load(opts)
 	if err != nil {
-		log.Fatal("Failed to load config:", err)
+		fatal("failed to load config", err)
 	}
 
-	log.Printf("Starting %s v%s in %s mode", cfg.App.Name, cfg.App.Version, cfg.App.Environment)
+	// 初始化日志，之后 slog 和标准库 log 的输出都经过该记录器
+	logger, logCloser, err := newLogger(cfg.Log)
+	if err != nil {
+		fatal("failed to set up logging", err)
+	}
+	slog.SetDefault(logger)
+	if cfg.Log.Unredacted {
+		slog.Warn("log redaction is disabled, personal data and credentials will be written to logs")
+	}
+
+	slog.Info("starting service",
+		slog.String("name", cfg.App.Name),
+		slog.String("version", cfg.App.Version),
+		slog.String("environment", cfg.App.Environment),
+	)
+
+	// 初始化链路追踪，未启用时全局 TracerProvider 为空操作实现
+	shutdownTracing := func(context.Context) error { return nil }
+	if cfg.Tracing.Enabled {
+		shutdownTracing, err = telemetry.Setup(context.Background(), telemetry.Config{
+			ServiceName:    cfg.App.Name,
+			ServiceVersion: cfg.App.Version,
+			Environment:    cfg.App.Environment,
+			Exporter:       cfg.Tracing.Exporter,
+			File:           cfg.Tracing.File,
+			Endpoint:       cfg.Tracing.Endpoint,
+			Insecure:       cfg.Tracing.Insecure,
+			SampleRatio:    cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			fatal("failed to set up tracing", err)
+		}
+	}
 
 	// 初始化数据库连接
 	maxLife, err := cfg.Database.GetMaxLifeDuration()
 	if err != nil {
-		log.Fatal("Failed to parse max life duration:", err)
+		fatal("failed to parse max life duration", err)
 	}
 
 	db, err := mariadb.NewFromDSNWithPool(
@@ -40,30 +101,387 @@ func main() {
 		maxLife,
 	)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal("failed to connect to database", err)
+	}
+	db.Logger = logging.NewGORMLogger(logger)
+
+	// 初始化指标，关闭时各组件收到 nil 不采集；GORM 语句耗时在迁移之前开始采集
+	var appMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		appMetrics = metrics.New()
+		if err := appMetrics.InstrumentGORM(db); err != nil {
+			fatal("failed to instrument database", err)
+		}
+	}
+
+	if cfg.Tracing.Enabled {
+		if err := telemetry.InstrumentGORM(db); err != nil {
+			fatal("failed to instrument database for tracing", err)
+		}
 	}
 
 	// 执行数据库迁移
 	if err := database.MigrateWithLog(db); err != nil {
-		log.Fatal("Failed to migrate database:", err)
+		fatal("failed to migrate database", err)
 	}
 
 	// 初始化仓储
-	userRepo := persistence.NewUserRepository(db)
+	var (
+		userRepo     domain.UserRepository     = persistence.NewUserRepository(db)
+		userRoleRepo domain.UserRoleRepository = persistence.NewUserRoleRepository(db)
+		sessionRepo  domain.SessionRepository  = persistence.NewSessionRepository(db)
+	)
+	if cfg.Tracing.Enabled {
+		userRepo = telemetry.TraceUserRepository(userRepo)
+		userRoleRepo = telemetry.TraceUserRoleRepository(userRoleRepo)
+		sessionRepo = telemetry.TraceSessionRepository(sessionRepo)
+	}
 
 	// 初始化领域服务
-	userDomainSvc := domain.NewUserDomainService(userRepo)
+	userDomainSvc := domain.NewUserDomainService(userRepo, domain.IdentifierReusePolicy(cfg.Users.IdentifierReusePolicy))
+
+	// 初始化密码哈希器
+	passwordHasher, err := password.New(password.Config{
+		Algorithm:  cfg.Security.Password.Algorithm,
+		BcryptCost: cfg.Security.Password.BcryptCost,
+		Argon2: password.Argon2Params{
+			Memory:      cfg.Security.Password.Argon2Memory,
+			Iterations:  cfg.Security.Password.Argon2Iterations,
+			Parallelism: cfg.Security.Password.Argon2Parallelism,
+		},
+	})
+	if err != nil {
+		fatal("failed to create password hasher", err)
+	}
+
+	// 初始化用户事件日志
+	var userEventLog domain.UserEventLog = eventbus.NewUserEventLog(cfg.Events.BufferSize)
+	if appMetrics != nil {
+		userEventLog = appMetrics.InstrumentUserEventLog(userEventLog)
+	}
 
 	// 初始化应用服务
-	userAppSvc := application.NewUserAppService(userRepo, userDomainSvc)
+	userAppSvc := application.NewUserAppService(userRepo, userRoleRepo, userDomainSvc, passwordHasher, userEventLog)
+
+	// 初始化认证服务
+	accessTTL, err := cfg.Security.Token.GetAccessTTL()
+	if err != nil {
+		fatal("failed to parse access token TTL", err)
+	}
+	refreshTTL, err := cfg.Security.Token.GetRefreshTTL()
+	if err != nil {
+		fatal("failed to parse refresh token TTL", err)
+	}
+	tokenKeys := make([]token.KeyConfig, 0, len(cfg.Security.Token.Keys))
+	for _, key := range cfg.Security.Token.Keys {
+		tokenKeys = append(tokenKeys, token.KeyConfig{
+			ID:             key.ID,
+			Algorithm:      key.Algorithm,
+			Secret:         key.Secret,
+			PrivateKeyFile: key.PrivateKeyFile,
+			PublicKeyFile:  key.PublicKeyFile,
+		})
+	}
+	tokenIssuer, err := token.NewIssuer(token.Config{
+		Issuer:       cfg.Security.Token.Issuer,
+		AccessTTL:    accessTTL,
+		SigningKeyID: cfg.Security.Token.SigningKeyID,
+		Keys:         tokenKeys,
+	})
+	if err != nil {
+		fatal("failed to create token issuer", err)
+	}
+	tokenVerifier, err := token.NewVerifier(token.Config{
+		Issuer: cfg.Security.Token.Issuer,
+		Keys:   tokenKeys,
+	})
+	if err != nil {
+		fatal("failed to create token verifier", err)
+	}
+	apiKeys := make([]auth.APIKey, 0, len(cfg.Security.Auth.APIKeys))
+	for _, key := range cfg.Security.Auth.APIKeys {
+		apiKeys = append(apiKeys, auth.APIKey{Name: key.Name, SHA256: key.SHA256, Scopes: key.Scopes})
+	}
+	authenticator, err := auth.NewAuthenticator(tokenVerifier, apiKeys)
+	if err != nil {
+		fatal("failed to create authenticator", err)
+	}
+	defaultPublic := cfg.Security.Auth.DefaultPolicy == "public"
+	grpcAuthPolicy := auth.NewPolicy(defaultPublic,
+		slices.Concat(grpc.DefaultPublicMethods, cfg.Security.Auth.PublicMethods),
+		cfg.Security.Auth.ProtectedMethods,
+	)
+	// 指标端点只有显式配置为公开时才无需认证
+	httpPublicRoutes := slices.Concat(http.DefaultPublicRoutes, cfg.Security.Auth.PublicRoutes)
+	httpProtectedRoutes := slices.Clone(cfg.Security.Auth.ProtectedRoutes)
+	if cfg.Metrics.Enabled {
+		if cfg.Metrics.Public {
+			httpPublicRoutes = append(httpPublicRoutes, cfg.Metrics.Path)
+		} else {
+			httpProtectedRoutes = append(httpProtectedRoutes, cfg.Metrics.Path)
+		}
+	}
+	httpAuthPolicy := auth.NewPolicy(defaultPublic,
+		httpPublicRoutes,
+		httpProtectedRoutes,
+	)
+	authAppSvc := application.NewAuthAppService(userRepo, sessionRepo, passwordHasher, tokenIssuer, refreshTTL)
+
+	// 初始化健康检查
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("failed to get database pool", err)
+	}
+	if appMetrics != nil {
+		if err := appMetrics.RegisterDBStats(sqlDB, cfg.Database.Database); err != nil {
+			fatal("failed to register database pool metrics", err)
+		}
+	}
+	checkInterval, err := cfg.Health.GetCheckInterval()
+	if err != nil {
+		fatal("failed to parse health check interval", err)
+	}
+	checkTimeout, err := cfg.Health.GetCheckTimeout()
+	if err != nil {
+		fatal("failed to parse health check timeout", err)
+	}
+	healthChecker := grpc.NewHealthChecker(sqlDB, checkInterval, checkTimeout)
+
+	// 初始化限流器，gRPC 和 HTTP 共用同一份令牌桶
+	rateLimiter, err := newRateLimiter(cfg.RateLimit)
+	if err != nil {
+		fatal("failed to create rate limiter", err)
+	}
+
+	// 监视配置文件，日志级别、限流规则和连接池大小修改后无需重启
+	configWatcher, err := config.NewWatcher(opts, cfg)
+	if err != nil {
+		fatal("failed to watch config", err)
+	}
+	subscribeConfig(configWatcher, logger, rateLimiter, sqlDB)
+
+	// 初始化TLS证书，证书文件轮换后自动重新加载
+	grpcTLS, err := newTLSReloader(cfg.GRPC.TLS)
+	if err != nil {
+		fatal("failed to load gRPC TLS certificate", err)
+	}
+	httpTLS, err := newTLSReloader(cfg.App.TLS)
+	if err != nil {
+		fatal("failed to load HTTP TLS certificate", err)
+	}
+	var grpcTLSConfig, httpTLSConfig *tls.Config
+	if grpcTLS != nil {
+		grpcTLSConfig = grpcTLS.ServerConfig()
+	}
+	if httpTLS != nil {
+		httpTLSConfig = httpTLS.ServerConfig("h2", "http/1.1")
+	}
+
+	// 初始化gRPC、HTTP服务器
+	grpcServer, err := grpc.NewServer(&cfg.GRPC, grpcTLSConfig, userAppSvc, authAppSvc, healthChecker, grpc.NewAuthInterceptor(authenticator, grpcAuthPolicy), rateLimiter, appMetrics)
+	if err != nil {
+		fatal("failed to create gRPC server", err)
+	}
+	httpServer, err := http.NewServer(userAppSvc, &cfg.App, authenticator, httpAuthPolicy, rateLimiter, httpTLSConfig, appMetrics, cfg.Metrics.Path)
+	if err != nil {
+		fatal("failed to create HTTP server", err)
+	}
+
+	// 统一管理各组件的启动和停止
+	shutdownTimeout, err := cfg.App.GetShutdownTimeout()
+	if err != nil {
+		fatal("failed to parse shutdown timeout", err)
+	}
+	manager := lifecycle.New(shutdownTimeout)
+	manager.Add(lifecycle.Component{
+		Name: "grpc",
+		Start: func(ctx context.Context) error {
+			return grpcServer.Start(cfg.GRPC.GetAddress())
+		},
+		Stop: grpcServer.Shutdown,
+	})
+	manager.Add(lifecycle.Component{
+		Name: "http",
+		Start: func(ctx context.Context) error {
+			return httpServer.Start()
+		},
+		Stop: httpServer.Stop,
+	})
+	manager.Add(lifecycle.Component{
+		Name: "config-watcher",
+		Start: func(ctx context.Context) error {
+			configWatcher.Run(ctx)
+			return nil
+		},
+	})
+	manager.Add(lifecycle.Component{
+		Name: "health-checker",
+		Start: func(ctx context.Context) error {
+			healthChecker.Run(ctx)
+			return nil
+		},
+	})
+	for _, r := range []struct {
+		name     string
+		reloader *tlsconfig.Reloader
+	}{{"grpc-tls-reloader", grpcTLS}, {"http-tls-reloader", httpTLS}} {
+		reloader := r.reloader
+		if reloader == nil {
+			continue
+		}
+		manager.Add(lifecycle.Component{
+			Name: r.name,
+			Start: func(ctx context.Context) error {
+				reloader.Run(ctx)
+				return nil
+			},
+		})
+	}
+	manager.AddCloser("tracing", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return shutdownTracing(ctx)
+	})
+	manager.AddCloser("database", sqlDB.Close)
+
+	if err := manager.Run(context.Background()); err != nil {
+		slog.Error("server exited with error", slog.Any("error", err))
+		logCloser.Close()
+		os.Exit(1)
+	}
+	logCloser.Close()
+}
+
+// fatal 记录启动失败的原因后退出
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
 
-	// 初始化gRPC服务器
-	grpcServer := grpc.NewServer(userAppSvc)
+// newLogger 根据配置创建日志记录器
+func newLogger(cfg config.LogConfig) (*slog.Logger, io.Closer, error) {
+	logCfg, err := loggingConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return logging.New(logCfg)
+}
+
+// loggingConfig 将日志配置转换为 logging.Config
+func loggingConfig(cfg config.LogConfig) (logging.Config, error) {
+	rotateInterval, err := cfg.GetRotateInterval()
+	if err != nil {
+		return logging.Config{}, err
+	}
+	packages := make(map[string]string, len(cfg.Packages))
+	for _, p := range cfg.Packages {
+		packages[p.Package] = p.Level
+	}
+	return logging.Config{
+		Level:          cfg.Level,
+		Format:         cfg.Format,
+		Output:         cfg.Output,
+		Filename:       cfg.Filename,
+		MaxSizeMB:      cfg.MaxSize,
+		MaxBackups:     cfg.MaxBackups,
+		MaxAgeDays:     cfg.MaxAge,
+		Compress:       cfg.Compress,
+		RotateInterval: rotateInterval,
+		Unredacted:     cfg.Unredacted,
+		Packages:       packages,
+	}, nil
+}
+
+// newRateLimiter 根据配置创建限流器，未启用时限流器处于停用状态，之后可通过配置重新加载启用
+func newRateLimiter(cfg config.RateLimitConfig) (*ratelimit.Limiter, error) {
+	rules, defaultRule := rateLimitRules(cfg)
+	limiter, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), rules, defaultRule)
+	if err != nil {
+		return nil, err
+	}
+	limiter.SetEnabled(cfg.Enabled)
+	return limiter, nil
+}
 
-	// 启动gRPC服务器
-	grpcAddress := cfg.GRPC.GetAddress()
-	log.Printf("gRPC server starting on %s", grpcAddress)
-	if err := grpcServer.Start(grpcAddress); err != nil {
-		log.Fatal("Failed to start gRPC server:", err)
+// rateLimitRules 将限流配置转换为规则，默认规则的速率为 0 时不设置默认规则
+func rateLimitRules(cfg config.RateLimitConfig) ([]ratelimit.Rule, *ratelimit.Rule) {
+	toRule := func(r config.RateLimitRuleConfig) ratelimit.Rule {
+		return ratelimit.Rule{
+			Match: r.Match,
+			KeyBy: ratelimit.KeyBy(r.KeyBy),
+			Limit: ratelimit.Limit{Rate: r.RequestsPerSecond, Burst: r.Burst},
+		}
+	}
+	rules := make([]ratelimit.Rule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		rules = append(rules, toRule(r))
+	}
+	var defaultRule *ratelimit.Rule
+	if cfg.Default.RequestsPerSecond > 0 {
+		rule := toRule(cfg.Default)
+		rule.Match = "*"
+		defaultRule = &rule
+	}
+	return rules, defaultRule
+}
+
+// subscribeConfig 订阅可在运行时调整的配置节，其余配置节修改后需重启
+func subscribeConfig(w *config.Watcher, logger *slog.Logger, limiter *ratelimit.Limiter, sqlDB *sql.DB) {
+	w.Subscribe("log", func(old, cfg *config.Config) error {
+		logCfg, err := loggingConfig(cfg.Log)
+		if err == nil {
+			err = logging.Reconfigure(logger, logCfg)
+		}
+		if err != nil {
+			return err
+		}
+		if cfg.Log.Unredacted && !old.Log.Unredacted {
+			slog.Warn("log redaction is disabled, personal data and credentials will be written to logs")
+		}
+		if cfg.Log.Format != old.Log.Format || cfg.Log.Output != old.Log.Output || cfg.Log.Filename != old.Log.Filename {
+			slog.Warn("log format and output changes require a restart")
+		}
+		return nil
+	})
+
+	w.Subscribe("rate_limit", func(_, cfg *config.Config) error {
+		if err := limiter.Update(rateLimitRules(cfg.RateLimit)); err != nil {
+			return err
+		}
+		limiter.SetEnabled(cfg.RateLimit.Enabled)
+		return nil
+	})
+
+	w.Subscribe("database", func(old, cfg *config.Config) error {
+		maxLife, err := cfg.Database.GetMaxLifeDuration()
+		if err != nil {
+			return err
+		}
+		sqlDB.SetMaxOpenConns(cfg.Database.MaxOpen)
+		sqlDB.SetMaxIdleConns(cfg.Database.MaxIdle)
+		sqlDB.SetConnMaxLifetime(maxLife)
+		if cfg.Database.GetDSN() != old.Database.GetDSN() {
+			slog.Warn("database connection changes require a restart")
+		}
+		return nil
+	})
+}
+
+// newTLSReloader 根据配置加载证书，未启用时返回 nil
+func newTLSReloader(cfg config.TLSConfig) (*tlsconfig.Reloader, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	reloadInterval, err := cfg.GetReloadInterval()
+	if err != nil {
+		return nil, err
 	}
+	return tlsconfig.NewReloader(tlsconfig.Config{
+		CertFile:       cfg.CertFile,
+		KeyFile:        cfg.KeyFile,
+		ClientCAFile:   cfg.ClientCAFile,
+		ClientAuth:     tlsconfig.ClientAuth(cfg.ClientAuth),
+		MinVersion:     cfg.MinVersion,
+		ReloadInterval: reloadInterval,
+	})
 }
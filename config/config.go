@@ -2,29 +2,43 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
 // Config 应用配置
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Database DatabaseConfig `mapstructure:"database"`
-	GRPC     GRPCConfig     `mapstructure:"grpc"`
-	Log      LogConfig      `mapstructure:"log"`
+	App       AppConfig       `mapstructure:"app"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	GRPC      GRPCConfig      `mapstructure:"grpc"`
+	Log       LogConfig       `mapstructure:"log"`
+	Security  SecurityConfig  `mapstructure:"security"`
+	Events    EventsConfig    `mapstructure:"events"`
+	Users     UsersConfig     `mapstructure:"users"`
+	Health    HealthConfig    `mapstructure:"health"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Metrics   MetricsConfig   `mapstructure:"metrics"`
+	Tracing   TracingConfig   `mapstructure:"tracing"`
 }
 
 // AppConfig 应用配置
 type AppConfig struct {
-	Name         string `mapstructure:"name"`
-	Version      string `mapstructure:"version"`
-	Environment  string `mapstructure:"environment"`
-	Debug        bool   `mapstructure:"debug"`
-	Port         int    `mapstructure:"port"` // HTTP端口
-	Host         string `mapstructure:"host"` // HTTP主机
-	ReadTimeout  string `mapstructure:"read_timeout"`
-	WriteTimeout string `mapstructure:"write_timeout"`
+	Name            string    `mapstructure:"name"`
+	Version         string    `mapstructure:"version"`
+	Environment     string    `mapstructure:"environment"`
+	Debug           bool      `mapstructure:"debug"`
+	Port            int       `mapstructure:"port"` // HTTP端口
+	Host            string    `mapstructure:"host"` // HTTP主机
+	ReadTimeout     string    `mapstructure:"read_timeout"`
+	WriteTimeout    string    `mapstructure:"write_timeout"`
+	ShutdownTimeout string    `mapstructure:"shutdown_timeout"` // 收到停止信号后排空在途请求的时限
+	TLS             TLSConfig `mapstructure:"tls"`              // HTTPS 证书和客户端证书校验
 }
 
 // DatabaseConfig 数据库配置
@@ -42,41 +56,236 @@ type DatabaseConfig struct {
 }
 
 // GRPCConfig gRPC配置
+// 时长配置为空或 "0" 表示不限制（使用 grpc-go 的默认值）
 type GRPCConfig struct {
-	Host         string `mapstructure:"host"`
-	Port         int    `mapstructure:"port"`
-	Reflection   bool   `mapstructure:"reflection"`
-	ReadTimeout  string `mapstructure:"read_timeout"`
-	WriteTimeout string `mapstructure:"write_timeout"`
+	Host                 string              `mapstructure:"host"`
+	Port                 int                 `mapstructure:"port"`
+	Reflection           bool                `mapstructure:"reflection"`
+	ReadTimeout          string              `mapstructure:"read_timeout"`           // 新连接完成握手的时限
+	DefaultDeadline      string              `mapstructure:"default_deadline"`       // 客户端未设置截止时间时一元RPC的默认时限，流式RPC不受影响
+	MaxRecvMsgSize       int                 `mapstructure:"max_recv_msg_size"`      // 单条接收消息的最大字节数
+	MaxSendMsgSize       int                 `mapstructure:"max_send_msg_size"`      // 单条发送消息的最大字节数
+	MaxConcurrentStreams uint32              `mapstructure:"max_concurrent_streams"` // 每个连接的最大并发流数，0 表示不限制
+	Keepalive            GRPCKeepaliveConfig `mapstructure:"keepalive"`
+	TLS                  TLSConfig           `mapstructure:"tls"`
+}
+
+// TLSConfig 服务端 TLS 配置，证书文件轮换后自动重新加载
+type TLSConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`
+	CertFile       string `mapstructure:"cert_file"`
+	KeyFile        string `mapstructure:"key_file"`
+	ClientCAFile   string `mapstructure:"client_ca_file"`  // 配置后默认要求客户端证书（mTLS）
+	ClientAuth     string `mapstructure:"client_auth"`     // none / request / require，为空时按是否配置 client_ca_file 决定
+	MinVersion     string `mapstructure:"min_version"`     // 1.2 / 1.3
+	ReloadInterval string `mapstructure:"reload_interval"` // 证书文件检查间隔
+}
+
+// GRPCKeepaliveConfig gRPC 连接保活和连接寿命配置
+type GRPCKeepaliveConfig struct {
+	Time                  string `mapstructure:"time"`                     // 连接空闲多久后服务端发送 ping
+	Timeout               string `mapstructure:"timeout"`                  // 等待 ping 响应的时限，超时关闭连接
+	MinTime               string `mapstructure:"min_time"`                 // 允许客户端发送 ping 的最小间隔，过于频繁的客户端会被断开
+	PermitWithoutStream   bool   `mapstructure:"permit_without_stream"`    // 是否允许客户端在没有活动流时发送 ping
+	MaxConnectionIdle     string `mapstructure:"max_connection_idle"`      // 连接空闲多久后关闭
+	MaxConnectionAge      string `mapstructure:"max_connection_age"`       // 连接最长存活时间，到期后发送 GOAWAY，便于负载重新均衡
+	MaxConnectionAgeGrace string `mapstructure:"max_connection_age_grace"` // 到期后等待进行中RPC完成的时限
 }
 
 // LogConfig 日志配置
 type LogConfig struct {
-	Level    string `mapstructure:"level"`
-	Format   string `mapstructure:"format"`
-	Output   string `mapstructure:"output"`
-	Filename string `mapstructure:"filename"`
+	Level          string             `mapstructure:"level"`           // debug / info / warn / error
+	Format         string             `mapstructure:"format"`          // json / text
+	Output         string             `mapstructure:"output"`          // stdout / stderr / file
+	Filename       string             `mapstructure:"filename"`        // output 为 file 时的日志文件
+	MaxSize        int                `mapstructure:"max_size"`        // 单个文件的最大大小（MB），超过后轮转
+	MaxBackups     int                `mapstructure:"max_backups"`     // 保留的历史文件数，0 表示全部保留
+	MaxAge         int                `mapstructure:"max_age"`         // 历史文件保留天数，0 表示不按时间清理
+	Compress       bool               `mapstructure:"compress"`        // 是否压缩历史文件
+	RotateInterval string             `mapstructure:"rotate_interval"` // 按时间轮转的间隔，如 "24h"，为空时只按大小轮转
+	Packages       []LogPackageConfig `mapstructure:"packages"`        // 按包覆盖日志级别
+	Unredacted     bool               `mapstructure:"unredacted"`      // 关闭日志脱敏以便排查问题，生产环境不允许开启
+}
+
+// LogPackageConfig 按包覆盖的日志级别
+type LogPackageConfig struct {
+	Package string `mapstructure:"package"` // 包的导入路径或其结尾部分，同时作用于子包，如 "internal/interfaces/grpc"、"gorm.io/gorm"
+	Level   string `mapstructure:"level"`
+}
+
+// GetRotateInterval 获取按时间轮转的间隔，未配置时为 0
+func (c *LogConfig) GetRotateInterval() (time.Duration, error) {
+	if c.RotateInterval == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(c.RotateInterval)
+}
+
+// SecurityConfig 安全配置
+type SecurityConfig struct {
+	Password PasswordConfig `mapstructure:"password"`
+	Token    TokenConfig    `mapstructure:"token"`
+	Auth     AuthConfig     `mapstructure:"auth"`
+}
+
+// PasswordConfig 密码哈希配置
+type PasswordConfig struct {
+	Algorithm         string `mapstructure:"algorithm"` // bcrypt / argon2id
+	BcryptCost        int    `mapstructure:"bcrypt_cost"`
+	Argon2Memory      uint32 `mapstructure:"argon2_memory"` // KiB
+	Argon2Iterations  uint32 `mapstructure:"argon2_iterations"`
+	Argon2Parallelism uint8  `mapstructure:"argon2_parallelism"`
+}
+
+// TokenConfig 访问令牌和刷新令牌配置
+type TokenConfig struct {
+	Issuer       string           `mapstructure:"issuer"`
+	AccessTTL    string           `mapstructure:"access_ttl"`
+	RefreshTTL   string           `mapstructure:"refresh_ttl"`
+	SigningKeyID string           `mapstructure:"signing_key_id"` // 签发使用的密钥ID，其余密钥只用于校验
+	Keys         []TokenKeyConfig `mapstructure:"keys"`
+}
+
+// TokenKeyConfig JWT签名密钥配置
+type TokenKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`        // HS256 / EdDSA
+	Secret         string `mapstructure:"secret"`           // HS256 共享密钥
+	PrivateKeyFile string `mapstructure:"private_key_file"` // EdDSA 私钥（PKCS#8 PEM）
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // EdDSA 公钥（PKIX PEM）
+}
+
+// AuthConfig 接口认证配置
+// 方法规则以 "/" 结尾时按前缀匹配整个服务；健康检查、反射和登录接口默认公开
+type AuthConfig struct {
+	DefaultPolicy    string         `mapstructure:"default_policy"`    // protected / public，未列出的方法使用该策略
+	PublicMethods    []string       `mapstructure:"public_methods"`    // gRPC 全方法名，如 /user.v1.UserService/GetUserById
+	ProtectedMethods []string       `mapstructure:"protected_methods"` // 优先级高于同长度的公开规则
+	PublicRoutes     []string       `mapstructure:"public_routes"`     // HTTP 路径
+	ProtectedRoutes  []string       `mapstructure:"protected_routes"`
+	APIKeys          []APIKeyConfig `mapstructure:"api_keys"`
+}
+
+// APIKeyConfig 服务账号API密钥配置
+type APIKeyConfig struct {
+	Name   string   `mapstructure:"name"`
+	SHA256 string   `mapstructure:"sha256"` // 密钥明文的 SHA-256（十六进制），明文不进入配置
+	Scopes []string `mapstructure:"scopes"`
+}
+
+// EventsConfig 用户变更事件配置
+type EventsConfig struct {
+	BufferSize int `mapstructure:"buffer_size"` // 内存中保留的事件数量，决定断线重连可回放的范围
+}
+
+// HealthConfig 健康检查配置
+type HealthConfig struct {
+	CheckInterval string `mapstructure:"check_interval"` // 数据库探测间隔
+	CheckTimeout  string `mapstructure:"check_timeout"`  // 单次探测超时
+}
+
+// RateLimitConfig 限流配置，gRPC 和 HTTP 入口共用
+type RateLimitConfig struct {
+	Enabled bool                  `mapstructure:"enabled"`
+	Store   string                `mapstructure:"store"`   // memory：单实例内限流
+	Default RateLimitRuleConfig   `mapstructure:"default"` // 未命中规则时使用，requests_per_second 为 0 表示不限流
+	Rules   []RateLimitRuleConfig `mapstructure:"rules"`
+}
+
+// RateLimitRuleConfig 限流规则配置
+type RateLimitRuleConfig struct {
+	Match             string  `mapstructure:"match"`               // gRPC 全方法名或 HTTP 路径（可带方法前缀，如 "POST /api/users"），以 "/" 结尾表示前缀
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"` // 令牌补充速率
+	Burst             int     `mapstructure:"burst"`               // 令牌桶容量
+	KeyBy             string  `mapstructure:"key_by"`              // principal / ip / global
+}
+
+// MetricsConfig Prometheus 指标配置，指标通过HTTP服务器暴露
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
+	Public  bool   `mapstructure:"public"` // 无需认证即可抓取；默认需要认证，不受 security.auth.default_policy 影响
 }
 
-// Load 加载配置
-func Load(configPath string) (*Config, error) {
-	viper.SetConfigFile(configPath)
-	viper.SetConfigType("yaml")
+// TracingConfig OpenTelemetry 链路追踪配置，服务名和版本取自 app 配置
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"`     // stdout / file / otlp
+	File        string  `mapstructure:"file"`         // file 导出器的输出文件
+	Endpoint    string  `mapstructure:"endpoint"`     // otlp 导出器的 Collector 地址（gRPC）
+	Insecure    bool    `mapstructure:"insecure"`     // otlp 使用明文连接
+	SampleRatio float64 `mapstructure:"sample_ratio"` // 0~1，上游已决定采样时遵循上游
+}
+
+// UsersConfig 用户管理配置
+type UsersConfig struct {
+	IdentifierReusePolicy string `mapstructure:"identifier_reuse_policy"` // after_purge / after_delete
+}
+
+// LoadOptions 配置加载选项
+// 优先级从低到高：默认值、基础配置文件、环境配置文件、APP_ 前缀的环境变量、命令行覆盖
+type LoadOptions struct {
+	Path      string            // 基础配置文件
+	Env       string            // 环境名，如 dev / prod，叠加基础配置文件同目录下的 config-<env>.yaml；为空时读取 APP_ENV
+	Overrides map[string]string // 命令行覆盖，键为配置路径，如 "database.host"
+}
+
+// EnvPrefix 环境变量前缀，配置路径中的 "." 替换为 "_"，如 APP_DATABASE_PASSWORD 覆盖 database.password
+const EnvPrefix = "APP"
+
+// Load 按层加载配置，合并后解析字符串中的 file: / env: 引用并验证
+// 环境配置文件中的列表整体替换基础配置中的同名列表
+func Load(opts LoadOptions) (*Config, error) {
+	v := viper.NewWithOptions(viper.ExperimentalBindStruct())
+	v.SetConfigType("yaml")
 
 	// 设置默认值
-	setDefaults()
+	setDefaults(v)
 
-	// 读取配置文件
-	if err := viper.ReadInConfig(); err != nil {
+	// 读取基础配置文件
+	v.SetConfigFile(opts.Path)
+	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// 解析配置到结构体
+	// 叠加环境配置文件
+	if env := profileEnv(opts); env != "" {
+		profilePath := ProfilePath(opts.Path, env)
+		v.SetConfigFile(profilePath)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read %s profile %s: %w", env, profilePath, err)
+		}
+	}
+
+	// 环境变量
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	// 命令行覆盖
+	for key, value := range opts.Overrides {
+		v.Set(key, value)
+	}
+
+	// 已移除的配置项仍被设置时拒绝启动，避免配置被静默忽略
+	if err := checkRemovedKeys(v); err != nil {
+		return nil, err
+	}
+
+	// 解析配置到结构体，环境变量和命令行中的列表以逗号分隔
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := v.Unmarshal(&config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	))); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// 解析密钥引用
+	if err := resolveRefs(reflect.ValueOf(&config).Elem(), ""); err != nil {
+		return nil, fmt.Errorf("failed to resolve config reference: %w", err)
+	}
+
 	// 验证配置
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
@@ -85,42 +294,112 @@ func Load(configPath string) (*Config, error) {
 	return &config, nil
 }
 
+// removedKeys 已移除的配置项及替代方式
+var removedKeys = map[string]string{
+	"grpc.write_timeout": "grpc-go has no per-write timeout; use grpc.default_deadline for unary RPCs and grpc.keepalive to close dead connections",
+}
+
+// checkRemovedKeys 配置文件、环境变量或命令行中设置了已移除的配置项时返回错误
+func checkRemovedKeys(v *viper.Viper) error {
+	for key, hint := range removedKeys {
+		if v.IsSet(key) {
+			return fmt.Errorf("config key %s has been removed: %s", key, hint)
+		}
+	}
+	return nil
+}
+
+// ProfilePath 返回环境配置文件路径：基础配置文件同目录下的 config-<env>.yaml
+func ProfilePath(basePath, env string) string {
+	return filepath.Join(filepath.Dir(basePath), "config-"+env+filepath.Ext(basePath))
+}
+
 // setDefaults 设置默认值
-func setDefaults() {
+func setDefaults(v *viper.Viper) {
 	// App默认值
-	viper.SetDefault("app.name", "go-protos")
-	viper.SetDefault("app.version", "1.0.0")
-	viper.SetDefault("app.environment", "development")
-	viper.SetDefault("app.debug", true)
-	viper.SetDefault("app.host", "0.0.0.0")
-	viper.SetDefault("app.port", 8080)
-	viper.SetDefault("app.read_timeout", "30s")
-	viper.SetDefault("app.write_timeout", "30s")
+	v.SetDefault("app.name", "go-protos")
+	v.SetDefault("app.version", "1.0.0")
+	v.SetDefault("app.environment", "development")
+	v.SetDefault("app.debug", true)
+	v.SetDefault("app.host", "0.0.0.0")
+	v.SetDefault("app.port", 8080)
+	v.SetDefault("app.read_timeout", "30s")
+	v.SetDefault("app.write_timeout", "30s")
+	v.SetDefault("app.shutdown_timeout", "15s")
+	v.SetDefault("app.tls.min_version", "1.2")
+	v.SetDefault("app.tls.reload_interval", "1m")
 
 	// Database默认值
-	viper.SetDefault("database.type", "mysql")
-	viper.SetDefault("database.host", "localhost")
-	viper.SetDefault("database.port", 3306)
-	viper.SetDefault("database.username", "root")
-	viper.SetDefault("database.password", "password")
-	viper.SetDefault("database.database", "testdb")
-	viper.SetDefault("database.charset", "utf8mb4")
-	viper.SetDefault("database.max_open", 50)
-	viper.SetDefault("database.max_idle", 10)
-	viper.SetDefault("database.max_life", "30m")
+	v.SetDefault("database.type", "mysql")
+	v.SetDefault("database.host", "localhost")
+	v.SetDefault("database.port", 3306)
+	v.SetDefault("database.username", "root")
+	v.SetDefault("database.password", "password")
+	v.SetDefault("database.database", "testdb")
+	v.SetDefault("database.charset", "utf8mb4")
+	v.SetDefault("database.max_open", 50)
+	v.SetDefault("database.max_idle", 10)
+	v.SetDefault("database.max_life", "30m")
 
 	// GRPC默认值
-	viper.SetDefault("grpc.host", "0.0.0.0")
-	viper.SetDefault("grpc.port", 9090) // gRPC使用不同端口
-	viper.SetDefault("grpc.reflection", true)
-	viper.SetDefault("grpc.read_timeout", "30s")
-	viper.SetDefault("grpc.write_timeout", "30s")
+	v.SetDefault("grpc.host", "0.0.0.0")
+	v.SetDefault("grpc.port", 9090) // gRPC使用不同端口
+	v.SetDefault("grpc.reflection", true)
+	v.SetDefault("grpc.read_timeout", "30s")
+	v.SetDefault("grpc.default_deadline", "30s")
+	v.SetDefault("grpc.max_recv_msg_size", 4<<20)
+	v.SetDefault("grpc.max_send_msg_size", 4<<20)
+	v.SetDefault("grpc.keepalive.time", "2h")
+	v.SetDefault("grpc.keepalive.timeout", "20s")
+	v.SetDefault("grpc.keepalive.min_time", "5m")
+	v.SetDefault("grpc.tls.min_version", "1.2")
+	v.SetDefault("grpc.tls.reload_interval", "1m")
 
 	// Log默认值
-	viper.SetDefault("log.level", "info")
-	viper.SetDefault("log.format", "json")
-	viper.SetDefault("log.output", "stdout")
-	viper.SetDefault("log.filename", "app.log")
+	v.SetDefault("log.level", "info")
+	v.SetDefault("log.format", "json")
+	v.SetDefault("log.output", "stdout")
+	v.SetDefault("log.filename", "app.log")
+	v.SetDefault("log.max_size", 100)
+	v.SetDefault("log.max_backups", 10)
+	v.SetDefault("log.max_age", 30)
+
+	// Security默认值
+	v.SetDefault("security.password.algorithm", "argon2id")
+	v.SetDefault("security.password.bcrypt_cost", 12)
+	v.SetDefault("security.password.argon2_memory", 64*1024)
+	v.SetDefault("security.password.argon2_iterations", 3)
+	v.SetDefault("security.password.argon2_parallelism", 2)
+	v.SetDefault("security.token.issuer", "go-protos")
+	v.SetDefault("security.token.access_ttl", "15m")
+	v.SetDefault("security.token.refresh_ttl", "720h")
+	v.SetDefault("security.auth.default_policy", "protected")
+
+	// Events默认值
+	v.SetDefault("events.buffer_size", 1024)
+
+	// 健康检查默认配置
+	v.SetDefault("health.check_interval", "10s")
+	v.SetDefault("health.check_timeout", "2s")
+
+	// 用户管理默认配置
+	v.SetDefault("users.identifier_reuse_policy", "after_purge")
+
+	// 限流默认配置
+	v.SetDefault("rate_limit.enabled", false)
+	v.SetDefault("rate_limit.store", "memory")
+
+	// 指标默认配置
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.path", "/metrics")
+	v.SetDefault("metrics.public", false)
+
+	// 链路追踪默认配置
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.exporter", "stdout")
+	v.SetDefault("tracing.file", "traces.jsonl")
+	v.SetDefault("tracing.endpoint", "localhost:4317")
+	v.SetDefault("tracing.sample_ratio", 1.0)
 }
 
 // Validate 验证配置
@@ -150,9 +429,178 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("app port and grpc port cannot be the same")
 	}
 
+	if err := validateLogLevel(c.Log.Level); err != nil {
+		return err
+	}
+	for _, p := range c.Log.Packages {
+		if p.Package == "" {
+			return fmt.Errorf("log package is required")
+		}
+		if err := validateLogLevel(p.Level); err != nil {
+			return fmt.Errorf("log package %s: %w", p.Package, err)
+		}
+	}
+	switch c.Log.Format {
+	case "json", "text":
+	default:
+		return fmt.Errorf("log format must be json or text")
+	}
+	switch c.Log.Output {
+	case "stdout", "stderr", "file":
+	default:
+		return fmt.Errorf("log output must be stdout, stderr or file")
+	}
+	if c.Log.Output == "file" && c.Log.Filename == "" {
+		return fmt.Errorf("log filename is required for file output")
+	}
+	if c.Log.Unredacted && c.App.Environment == "production" {
+		return fmt.Errorf("log unredacted cannot be enabled in production")
+	}
+
+	switch c.Security.Password.Algorithm {
+	case "bcrypt", "argon2id":
+	default:
+		return fmt.Errorf("security password algorithm must be bcrypt or argon2id")
+	}
+
+	if c.Security.Password.BcryptCost < 4 || c.Security.Password.BcryptCost > 31 {
+		return fmt.Errorf("security password bcrypt cost must be between 4 and 31")
+	}
+
+	if c.Security.Token.SigningKeyID == "" {
+		return fmt.Errorf("security token signing key id is required")
+	}
+	signingKeyFound := false
+	for _, key := range c.Security.Token.Keys {
+		switch key.Algorithm {
+		case "HS256", "EdDSA":
+		default:
+			return fmt.Errorf("security token key %q algorithm must be HS256 or EdDSA", key.ID)
+		}
+		if key.ID == c.Security.Token.SigningKeyID {
+			signingKeyFound = true
+		}
+	}
+	if !signingKeyFound {
+		return fmt.Errorf("security token signing key %q is not configured", c.Security.Token.SigningKeyID)
+	}
+
+	switch c.Security.Auth.DefaultPolicy {
+	case "protected", "public":
+	default:
+		return fmt.Errorf("security auth default policy must be protected or public")
+	}
+
+	switch c.Users.IdentifierReusePolicy {
+	case "after_purge", "after_delete":
+	default:
+		return fmt.Errorf("users identifier reuse policy must be after_purge or after_delete")
+	}
+
+	if err := c.App.TLS.validate(); err != nil {
+		return fmt.Errorf("app tls: %w", err)
+	}
+	if err := c.GRPC.TLS.validate(); err != nil {
+		return fmt.Errorf("grpc tls: %w", err)
+	}
+
+	if c.RateLimit.Enabled && c.RateLimit.Store != "memory" {
+		return fmt.Errorf("rate limit store must be memory")
+	}
+	if c.RateLimit.Default.RequestsPerSecond > 0 {
+		if err := c.RateLimit.Default.validate(); err != nil {
+			return fmt.Errorf("rate limit default: %w", err)
+		}
+	}
+	for _, rule := range c.RateLimit.Rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rate limit rule %q: %w", rule.Match, err)
+		}
+	}
+
+	if c.Database.MaxOpen < 0 || c.Database.MaxIdle < 0 {
+		return fmt.Errorf("database max open and max idle cannot be negative")
+	}
+	if _, err := c.Database.GetMaxLifeDuration(); err != nil {
+		return fmt.Errorf("database max life: %w", err)
+	}
+
+	if c.Metrics.Enabled && !strings.HasPrefix(c.Metrics.Path, "/") {
+		return fmt.Errorf("metrics path must start with /")
+	}
+
+	if c.Tracing.Enabled {
+		switch c.Tracing.Exporter {
+		case "stdout", "file", "otlp":
+		default:
+			return fmt.Errorf("tracing exporter must be stdout, file or otlp")
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing sample ratio must be between 0 and 1")
+		}
+	}
+
+	return nil
+}
+
+// validateLogLevel 验证日志级别，空字符串视为 info
+func validateLogLevel(level string) error {
+	if level == "" {
+		return nil
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	return nil
+}
+
+// validate 验证限流规则
+func (r *RateLimitRuleConfig) validate() error {
+	if r.RequestsPerSecond <= 0 {
+		return fmt.Errorf("requests per second must be positive")
+	}
+	if r.Burst < 1 {
+		return fmt.Errorf("burst must be at least 1")
+	}
+	switch r.KeyBy {
+	case "principal", "ip", "global":
+	default:
+		return fmt.Errorf("key by must be principal, ip or global")
+	}
 	return nil
 }
 
+// validate 验证TLS配置
+func (c *TLSConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return fmt.Errorf("cert file and key file are required")
+	}
+	switch c.ClientAuth {
+	case "", "none":
+	case "request", "require":
+		if c.ClientCAFile == "" {
+			return fmt.Errorf("client auth %s requires client ca file", c.ClientAuth)
+		}
+	default:
+		return fmt.Errorf("client auth must be none, request or require")
+	}
+	switch c.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		return fmt.Errorf("min version must be 1.2 or 1.3")
+	}
+	return nil
+}
+
+// GetReloadInterval 获取证书文件检查间隔
+func (c *TLSConfig) GetReloadInterval() (time.Duration, error) {
+	return time.ParseDuration(c.ReloadInterval)
+}
+
 // GetDSN 获取数据库连接字符串
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
@@ -183,3 +631,28 @@ func (c *AppConfig) GetReadTimeout() (time.Duration, error) {
 func (c *AppConfig) GetWriteTimeout() (time.Duration, error) {
 	return time.ParseDuration(c.WriteTimeout)
 }
+
+// GetShutdownTimeout 获取停止排空时限
+func (c *AppConfig) GetShutdownTimeout() (time.Duration, error) {
+	return time.ParseDuration(c.ShutdownTimeout)
+}
+
+// GetAccessTTL 获取访问令牌有效期
+func (c *TokenConfig) GetAccessTTL() (time.Duration, error) {
+	return time.ParseDuration(c.AccessTTL)
+}
+
+// GetRefreshTTL 获取刷新令牌（会话）有效期
+func (c *TokenConfig) GetRefreshTTL() (time.Duration, error) {
+	return time.ParseDuration(c.RefreshTTL)
+}
+
+// GetCheckInterval 获取健康检查探测间隔
+func (c *HealthConfig) GetCheckInterval() (time.Duration, error) {
+	return time.ParseDuration(c.CheckInterval)
+}
+
+// GetCheckTimeout 获取健康检查探测超时
+func (c *HealthConfig) GetCheckTimeout() (time.Duration, error) {
+	return time.ParseDuration(c.CheckTimeout)
+}
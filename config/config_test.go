@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfigPath 测试使用的基础配置，环境配置位于同一目录
+const testConfigPath = "testdata/config.yaml"
+
+func TestLoad_Layers(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "from-env-ref")
+	t.Setenv("APP_GRPC_PORT", "9191")
+	t.Setenv("APP_SECURITY_AUTH_PUBLIC_ROUTES", "/a,/b")
+
+	cfg, err := Load(LoadOptions{
+		Path:      testConfigPath,
+		Env:       "dev",
+		Overrides: map[string]string{"grpc.port": "9292", "database.host": "db"},
+	})
+	require.NoError(t, err)
+
+	// 环境配置覆盖基础配置，未覆盖的保留基础配置
+	assert.Equal(t, "glauy", cfg.Database.Username)
+	assert.Equal(t, "text", cfg.Log.Format)
+	assert.Equal(t, "dev-hs256", cfg.Security.Token.SigningKeyID)
+	assert.Len(t, cfg.Security.Token.Keys, 1)
+	assert.Equal(t, 8080, cfg.App.Port)
+
+	// 环境变量和命令行
+	assert.Equal(t, []string{"/a", "/b"}, cfg.Security.Auth.PublicRoutes)
+	assert.Equal(t, 9292, cfg.GRPC.Port)
+	assert.Equal(t, "db", cfg.Database.Host)
+
+	assert.Equal(t, "from-env-ref", cfg.Database.Password)
+}
+
+func TestLoad_EnvFromVariable(t *testing.T) {
+	t.Setenv("APP_ENV", "missing")
+
+	_, err := Load(LoadOptions{Path: testConfigPath})
+	assert.ErrorContains(t, err, "config-missing.yaml")
+}
+
+func TestLoad_MissingEnvRef(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "")
+	require.NoError(t, os.Unsetenv("DB_PASSWORD"))
+
+	_, err := Load(LoadOptions{Path: testConfigPath, Env: "dev"})
+	assert.ErrorContains(t, err, "database.password: environment variable DB_PASSWORD is not set")
+}
+
+func TestLoad_RemovedKey(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "from-env-ref")
+
+	_, err := Load(LoadOptions{Path: testConfigPath, Env: "dev", Overrides: map[string]string{"grpc.write_timeout": "30s"}})
+	assert.ErrorContains(t, err, "config key grpc.write_timeout has been removed")
+
+	t.Setenv("APP_GRPC_WRITE_TIMEOUT", "30s")
+	_, err = Load(LoadOptions{Path: testConfigPath, Env: "dev"})
+	assert.ErrorContains(t, err, "config key grpc.write_timeout has been removed")
+}
+
+func TestResolveRef(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secret, []byte("s3cret\n"), 0o600))
+
+	value, err := resolveRef("file:" + secret)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", value)
+
+	_, err = resolveRef("file:" + secret + ".missing")
+	assert.Error(t, err)
+
+	value, err = resolveRef("plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", value)
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// fileRefPrefix 以该前缀开头的字符串配置项取值为引用文件的内容（去掉结尾换行），如 "file:/run/secrets/db_password"
+	fileRefPrefix = "file:"
+	// envRefPrefix 以该前缀开头的字符串配置项取值为引用的环境变量，如 "env:DB_PASSWORD"
+	envRefPrefix = "env:"
+)
+
+// resolveRefs 递归替换结构体中所有字符串字段的 file: / env: 引用，path 为当前字段的配置路径，用于错误信息
+func resolveRefs(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			key := t.Field(i).Tag.Get("mapstructure")
+			if key == "" || !v.Field(i).CanSet() {
+				continue
+			}
+			if path != "" {
+				key = path + "." + key
+			}
+			if err := resolveRefs(v.Field(i), key); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveRefs(v.Index(i), path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		value, err := resolveRef(v.String())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.SetString(value)
+	}
+	return nil
+}
+
+// resolveRef 解析单个值，不带引用前缀的值原样返回
+func resolveRef(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, fileRefPrefix):
+		name := strings.TrimPrefix(value, fileRefPrefix)
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(value, envRefPrefix):
+		name := strings.TrimPrefix(value, envRefPrefix)
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return env, nil
+	default:
+		return value, nil
+	}
+}
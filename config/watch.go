@@ -0,0 +1,216 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce 编辑器保存时往往产生多个事件，最后一个事件之后等待该时长再重新加载
+const reloadDebounce = 200 * time.Millisecond
+
+// Subscriber 配置节变更后的回调，old 为变更前生效的配置
+// 回调只应用能在运行时安全调整的部分，其余部分仍需重启；返回错误时该配置节保留变更前的值
+type Subscriber func(old, cfg *Config) error
+
+// Watcher 监视基础配置文件和环境配置文件，变更后按 Load 的规则重新加载，
+// 通过 Validate 后把发生变化的配置节通知给订阅方；重新加载失败时保留当前配置，订阅方应用失败时保留该配置节
+type Watcher struct {
+	opts    LoadOptions
+	files   map[string]bool // 监视的配置文件（绝对路径）
+	watcher *fsnotify.Watcher
+
+	reloadMu    sync.Mutex // 串行化 Reload
+	mu          sync.Mutex
+	current     *Config
+	subscribers map[string][]Subscriber // 配置节（顶层键，如 "log"）-> 回调
+}
+
+// NewWatcher 创建配置监视器，current 为启动时 Load 得到的配置
+func NewWatcher(opts LoadOptions, current *Config) (*Watcher, error) {
+	// 固定环境名，之后的重新加载始终使用启动时的环境配置文件
+	opts.Env = profileEnv(opts)
+	files := make(map[string]bool)
+	paths := []string{opts.Path}
+	if opts.Env != "" {
+		paths = append(paths, ProfilePath(opts.Path, opts.Env))
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			watcher.Close()
+			return nil, err
+		}
+		files[abs] = true
+		// 监视所在目录而不是文件本身，原子替换（重命名覆盖）后仍能收到事件
+		dir := filepath.Dir(abs)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	return &Watcher{
+		opts:        opts,
+		files:       files,
+		watcher:     watcher,
+		current:     current,
+		subscribers: make(map[string][]Subscriber),
+	}, nil
+}
+
+// Subscribe 订阅配置节的变更，section 为顶层配置键（如 "log"、"rate_limit"），不存在时 panic
+func (w *Watcher) Subscribe(section string, fn Subscriber) {
+	if !isSection(section) {
+		panic(fmt.Sprintf("config: unknown section %q", section))
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers[section] = append(w.subscribers[section], fn)
+}
+
+// Current 返回当前生效的配置
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Run 监视配置文件直到 ctx 结束
+func (w *Watcher) Run(ctx context.Context) {
+	defer w.watcher.Close()
+
+	timer := time.NewTimer(reloadDebounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.files[filepath.Clean(event.Name)] && !event.Has(fsnotify.Chmod) {
+				timer.Reset(reloadDebounce)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("config watcher error", slog.Any("error", err))
+		case <-timer.C:
+			if err := w.Reload(); err != nil {
+				slog.Error("config reload failed", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// Reload 重新加载配置，通过验证后通知变更的配置节；加载或验证出错时当前配置不变，
+// 订阅方返回错误的配置节保留变更前的值，其余配置节照常生效
+// 没有订阅方的配置节发生变化时只记录警告，需重启后生效
+func (w *Watcher) Reload() error {
+	// 串行执行重新加载，订阅方回调期间不持有 w.mu，回调中可以调用 Current 和 Subscribe
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	cfg, err := Load(w.opts)
+	if err != nil {
+		return err
+	}
+
+	type change struct {
+		field       int
+		section     string
+		subscribers []Subscriber
+	}
+	w.mu.Lock()
+	old := w.current
+	oldValue, newValue := reflect.ValueOf(old).Elem(), reflect.ValueOf(cfg).Elem()
+	t := newValue.Type()
+	var changes []change
+	for i := 0; i < t.NumField(); i++ {
+		if reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+		section := t.Field(i).Tag.Get("mapstructure")
+		changes = append(changes, change{field: i, section: section, subscribers: slices.Clone(w.subscribers[section])})
+	}
+	w.mu.Unlock()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// 回调拿到的 cfg 保持完整的新配置，生效的配置另外复制一份以便回退失败的配置节
+	applied := *cfg
+	appliedValue := reflect.ValueOf(&applied).Elem()
+	var (
+		changed []string
+		errs    []error
+	)
+	for _, c := range changes {
+		if len(c.subscribers) == 0 {
+			slog.Warn("config section changed but cannot be applied at runtime, restart required", slog.String("section", c.section))
+		}
+		var sectionErr error
+		for _, fn := range c.subscribers {
+			if err := fn(old, cfg); err != nil {
+				sectionErr = errors.Join(sectionErr, err)
+			}
+		}
+		if sectionErr != nil {
+			appliedValue.Field(c.field).Set(oldValue.Field(c.field))
+			errs = append(errs, fmt.Errorf("apply %s config: %w", c.section, sectionErr))
+			continue
+		}
+		changed = append(changed, c.section)
+	}
+
+	w.mu.Lock()
+	w.current = &applied
+	w.mu.Unlock()
+	if len(changed) > 0 {
+		slog.Info("config reloaded", slog.Any("changed", changed))
+	}
+	return errors.Join(errs...)
+}
+
+// profileEnv 返回生效的环境名，与 Load 的选择规则一致
+func profileEnv(opts LoadOptions) string {
+	if opts.Env != "" {
+		return opts.Env
+	}
+	return os.Getenv(EnvPrefix + "_ENV")
+}
+
+// isSection 判断是否为 Config 的顶层配置键
+func isSection(section string) bool {
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("mapstructure") == section {
+			return true
+		}
+	}
+	return false
+}
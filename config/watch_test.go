@@ -0,0 +1,116 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig 将测试基础配置按 replace 中的键值对替换后写入 path
+func writeConfig(t *testing.T, path string, replace ...string) {
+	t.Helper()
+	data, err := os.ReadFile(testConfigPath)
+	require.NoError(t, err)
+	content := strings.NewReplacer(replace...).Replace(string(data))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_HS256_SECRET", "secret")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path)
+
+	opts := LoadOptions{Path: path}
+	cfg, err := Load(opts)
+	require.NoError(t, err)
+	w, err := NewWatcher(opts, cfg)
+	require.NoError(t, err)
+
+	var logChanges, databaseChanges int
+	w.Subscribe("log", func(old, cfg *Config) error {
+		logChanges++
+		assert.Equal(t, "info", old.Log.Level)
+		assert.Equal(t, "debug", cfg.Log.Level)
+		// 回调期间不持有锁，可以读取当前配置
+		assert.Equal(t, "info", w.Current().Log.Level)
+		return nil
+	})
+	w.Subscribe("database", func(_, _ *Config) error { databaseChanges++; return nil })
+	assert.Panics(t, func() { w.Subscribe("unknown", func(_, _ *Config) error { return nil }) })
+
+	// 只通知发生变化的配置节
+	writeConfig(t, path, `level: "info"`, `level: "debug"`)
+	require.NoError(t, w.Reload())
+	assert.Equal(t, 1, logChanges)
+	assert.Equal(t, 0, databaseChanges)
+	assert.Equal(t, "debug", w.Current().Log.Level)
+
+	// 未通过验证时保留当前配置
+	writeConfig(t, path, `level: "info"`, `level: "verbose"`)
+	assert.ErrorContains(t, w.Reload(), "invalid log level")
+	assert.Equal(t, "debug", w.Current().Log.Level)
+	assert.Equal(t, 1, logChanges)
+}
+
+func TestWatcher_ReloadSubscriberError(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_HS256_SECRET", "secret")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path)
+
+	opts := LoadOptions{Path: path}
+	cfg, err := Load(opts)
+	require.NoError(t, err)
+	w, err := NewWatcher(opts, cfg)
+	require.NoError(t, err)
+
+	var maxOpen int
+	w.Subscribe("log", func(_, _ *Config) error { return errors.New("log sink unavailable") })
+	w.Subscribe("database", func(_, cfg *Config) error { maxOpen = cfg.Database.MaxOpen; return nil })
+
+	// 应用失败的配置节保留原值，其余配置节照常生效
+	writeConfig(t, path, `level: "info"`, `level: "debug"`, "max_open: 50", "max_open: 80")
+	assert.ErrorContains(t, w.Reload(), "log sink unavailable")
+	assert.Equal(t, "info", w.Current().Log.Level)
+	assert.Equal(t, 80, w.Current().Database.MaxOpen)
+	assert.Equal(t, 80, maxOpen)
+}
+
+func TestWatcher_Run(t *testing.T) {
+	t.Setenv("DB_PASSWORD", "secret")
+	t.Setenv("JWT_HS256_SECRET", "secret")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path)
+
+	opts := LoadOptions{Path: path}
+	cfg, err := Load(opts)
+	require.NoError(t, err)
+	w, err := NewWatcher(opts, cfg)
+	require.NoError(t, err)
+
+	var maxOpen atomic.Int64
+	w.Subscribe("database", func(_, cfg *Config) error {
+		maxOpen.Store(int64(cfg.Database.MaxOpen))
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	// 原子替换文件，与编辑器和配置下发工具的写法一致
+	tmp := path + ".tmp"
+	writeConfig(t, tmp, "max_open: 50", "max_open: 80")
+	require.NoError(t, os.Rename(tmp, path))
+
+	assert.Eventually(t, func() bool { return maxOpen.Load() == 80 }, 5*time.Second, 20*time.Millisecond)
+}
@@ -0,0 +1,49 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"go-protos/internal/application/commands"
+	"go-protos/internal/domain"
+)
+
+// AuthAppService 认证应用服务
+type AuthAppService struct {
+	authenticateHandler   *commands.AuthenticateCommandHandler
+	refreshSessionHandler *commands.RefreshSessionCommandHandler
+	logoutHandler         *commands.LogoutCommandHandler
+}
+
+// NewAuthAppService 创建认证应用服务，refreshTTL 为刷新令牌（会话）的有效期
+func NewAuthAppService(
+	userRepo domain.UserRepository,
+	sessionRepo domain.SessionRepository,
+	passwordHasher domain.PasswordHasher,
+	tokenIssuer domain.TokenIssuer,
+	refreshTTL time.Duration,
+) *AuthAppService {
+	return &AuthAppService{
+		authenticateHandler:   commands.NewAuthenticateCommandHandler(userRepo, sessionRepo, passwordHasher, tokenIssuer, refreshTTL),
+		refreshSessionHandler: commands.NewRefreshSessionCommandHandler(userRepo, sessionRepo, tokenIssuer, refreshTTL),
+		logoutHandler:         commands.NewLogoutCommandHandler(sessionRepo),
+	}
+}
+
+func (s *AuthAppService) Authenticate(ctx context.Context, usernameOrEmail, password string) (*commands.AuthTokens, error) {
+	cmd := commands.AuthenticateCommand{
+		UsernameOrEmail: usernameOrEmail,
+		Password:        password,
+	}
+	return s.authenticateHandler.Handle(ctx, cmd)
+}
+
+func (s *AuthAppService) Refresh(ctx context.Context, refreshToken string) (*commands.AuthTokens, error) {
+	cmd := commands.RefreshSessionCommand{RefreshToken: refreshToken}
+	return s.refreshSessionHandler.Handle(ctx, cmd)
+}
+
+func (s *AuthAppService) Logout(ctx context.Context, refreshToken string) error {
+	cmd := commands.LogoutCommand{RefreshToken: refreshToken}
+	return s.logoutHandler.Handle(ctx, cmd)
+}
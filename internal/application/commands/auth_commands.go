@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go-protos/internal/domain"
+	"go-protos/pkg/tracing"
+
+	"github.com/google/uuid"
+)
+
+// AuthTokens 签发给客户端的令牌对
+type AuthTokens struct {
+	UserID                string
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+// AuthenticateCommand 登录命令
+type AuthenticateCommand struct {
+	UsernameOrEmail string
+	Password        string
+}
+
+// AuthenticateCommandHandler 登录命令处理器：校验密码，创建会话并签发令牌
+type AuthenticateCommandHandler struct {
+	userRepo       domain.UserRepository
+	sessionRepo    domain.SessionRepository
+	passwordHasher domain.PasswordHasher
+	tokenIssuer    domain.TokenIssuer
+	refreshTTL     time.Duration
+
+	dummyHashOnce sync.Once
+	dummyHash     string // 用户不存在时用于校验的哈希，使两种失败的耗时一致
+}
+
+// NewAuthenticateCommandHandler 创建命令处理器
+func NewAuthenticateCommandHandler(
+	userRepo domain.UserRepository,
+	sessionRepo domain.SessionRepository,
+	passwordHasher domain.PasswordHasher,
+	tokenIssuer domain.TokenIssuer,
+	refreshTTL time.Duration,
+) *AuthenticateCommandHandler {
+	return &AuthenticateCommandHandler{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		passwordHasher: passwordHasher,
+		tokenIssuer:    tokenIssuer,
+		refreshTTL:     refreshTTL,
+	}
+}
+
+// Handle 处理登录命令，用户不存在和密码错误统一返回 ErrInvalidCredentials
+func (h *AuthenticateCommandHandler) Handle(ctx context.Context, cmd AuthenticateCommand) (_ *AuthTokens, err error) {
+	ctx, span := tracing.Start(ctx, "AuthenticateCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	// 按用户名或邮箱查找用户
+	var user *domain.User
+	if strings.Contains(cmd.UsernameOrEmail, "@") {
+		user, err = h.userRepo.FindByEmail(ctx, cmd.UsernameOrEmail)
+	} else {
+		user, err = h.userRepo.FindByUsername(ctx, cmd.UsernameOrEmail)
+	}
+	if errors.Is(err, domain.ErrUserNotFound) || (err == nil && user == nil) {
+		// 同样执行一次完整的哈希校验，避免通过响应时间判断用户名或邮箱是否存在
+		_, _ = h.passwordHasher.Verify(h.getDummyHash(), cmd.Password)
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验密码，哈希参数过时时顺带升级
+	rehashed, err := user.CheckPassword(h.passwordHasher, cmd.Password)
+	if errors.Is(err, domain.ErrPasswordMismatch) {
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rehashed {
+		if err := h.userRepo.Save(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	// 创建会话
+	session, refreshToken, err := domain.NewSession(uuid.New().String(), user.ID, h.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.sessionRepo.Save(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return issueTokens(h.tokenIssuer, user, session, refreshToken)
+}
+
+// getDummyHash 按当前哈希算法和参数生成一次占位哈希
+func (h *AuthenticateCommandHandler) getDummyHash() string {
+	h.dummyHashOnce.Do(func() {
+		h.dummyHash, _ = h.passwordHasher.Hash(uuid.New().String())
+	})
+	return h.dummyHash
+}
+
+// RefreshSessionCommand 刷新令牌命令
+type RefreshSessionCommand struct {
+	RefreshToken string
+}
+
+// RefreshSessionCommandHandler 刷新令牌命令处理器：轮换刷新令牌并签发新的访问令牌
+type RefreshSessionCommandHandler struct {
+	userRepo    domain.UserRepository
+	sessionRepo domain.SessionRepository
+	tokenIssuer domain.TokenIssuer
+	refreshTTL  time.Duration
+}
+
+// NewRefreshSessionCommandHandler 创建命令处理器
+func NewRefreshSessionCommandHandler(
+	userRepo domain.UserRepository,
+	sessionRepo domain.SessionRepository,
+	tokenIssuer domain.TokenIssuer,
+	refreshTTL time.Duration,
+) *RefreshSessionCommandHandler {
+	return &RefreshSessionCommandHandler{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		tokenIssuer: tokenIssuer,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+// Handle 处理刷新令牌命令，同一刷新令牌并发刷新时只有一方成功
+func (h *RefreshSessionCommandHandler) Handle(ctx context.Context, cmd RefreshSessionCommand) (_ *AuthTokens, err error) {
+	ctx, span := tracing.Start(ctx, "RefreshSessionCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	session, err := findSessionByRefreshToken(ctx, h.sessionRepo, cmd.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if !session.IsActive(time.Now()) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// 用户已删除时撤销会话
+	user, err := h.userRepo.FindById(ctx, session.UserID)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+	if user == nil {
+		session.Revoke()
+		if err := h.sessionRepo.Save(ctx, session); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	// 轮换刷新令牌，旧令牌随即失效；条件保存，存储中的令牌已被其他请求轮换时失败
+	refreshToken, err := session.Rotate(h.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.sessionRepo.SaveRotated(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return issueTokens(h.tokenIssuer, user, session, refreshToken)
+}
+
+// LogoutCommand 退出登录命令
+type LogoutCommand struct {
+	RefreshToken string
+}
+
+// LogoutCommandHandler 退出登录命令处理器：撤销刷新令牌对应的会话
+type LogoutCommandHandler struct {
+	sessionRepo domain.SessionRepository
+}
+
+// NewLogoutCommandHandler 创建命令处理器
+func NewLogoutCommandHandler(sessionRepo domain.SessionRepository) *LogoutCommandHandler {
+	return &LogoutCommandHandler{
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Handle 处理退出登录命令，重复退出视为成功
+func (h *LogoutCommandHandler) Handle(ctx context.Context, cmd LogoutCommand) (err error) {
+	ctx, span := tracing.Start(ctx, "LogoutCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	session, err := findSessionByRefreshToken(ctx, h.sessionRepo, cmd.RefreshToken)
+	if err != nil {
+		return err
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+
+	session.Revoke()
+	return h.sessionRepo.Save(ctx, session)
+}
+
+// findSessionByRefreshToken 根据刷新令牌查找会话并校验令牌，不匹配时统一返回 ErrInvalidRefreshToken
+func findSessionByRefreshToken(ctx context.Context, sessionRepo domain.SessionRepository, refreshToken string) (*domain.Session, error) {
+	sessionID, secret, err := domain.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := sessionRepo.FindById(ctx, sessionID)
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !session.MatchesRefreshToken(secret) {
+		// 已轮换掉的令牌再次出现，说明令牌可能已泄露，撤销整个会话
+		if session.RevokedAt == nil && session.MatchesPreviousRefreshToken(secret) {
+			session.Revoke()
+			if err := sessionRepo.Save(ctx, session); err != nil {
+				return nil, err
+			}
+		}
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	return session, nil
+}
+
+// issueTokens 为会话签发访问令牌并组装令牌对
+func issueTokens(issuer domain.TokenIssuer, user *domain.User, session *domain.Session, refreshToken string) (*AuthTokens, error) {
+	accessToken, accessExpiresAt, err := issuer.IssueAccessToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthTokens{
+		UserID:                user.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: session.ExpiresAt,
+	}, nil
+}
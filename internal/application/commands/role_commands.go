@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+
+	"go-protos/internal/domain"
+	"go-protos/pkg/tracing"
+)
+
+// GrantUserRoleCommand 授予用户角色命令
+type GrantUserRoleCommand struct {
+	UserID string
+	Role   domain.Role
+}
+
+// GrantUserRoleCommandHandler 授予用户角色命令处理器
+type GrantUserRoleCommandHandler struct {
+	userRepo domain.UserRepository
+	roleRepo domain.UserRoleRepository
+}
+
+// NewGrantUserRoleCommandHandler 创建命令处理器
+func NewGrantUserRoleCommandHandler(
+	userRepo domain.UserRepository,
+	roleRepo domain.UserRoleRepository,
+) *GrantUserRoleCommandHandler {
+	return &GrantUserRoleCommandHandler{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+// Handle 处理授予角色命令，重复授予不报错
+func (h *GrantUserRoleCommandHandler) Handle(ctx context.Context, cmd GrantUserRoleCommand) (err error) {
+	ctx, span := tracing.Start(ctx, "GrantUserRoleCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	if err := validateAssignableRole(cmd.Role); err != nil {
+		return err
+	}
+	if err := ensureUserExists(ctx, h.userRepo, cmd.UserID); err != nil {
+		return err
+	}
+	return h.roleRepo.AddRole(ctx, cmd.UserID, cmd.Role)
+}
+
+// RevokeUserRoleCommand 撤销用户角色命令
+type RevokeUserRoleCommand struct {
+	UserID string
+	Role   domain.Role
+}
+
+// RevokeUserRoleCommandHandler 撤销用户角色命令处理器
+type RevokeUserRoleCommandHandler struct {
+	userRepo domain.UserRepository
+	roleRepo domain.UserRoleRepository
+}
+
+// NewRevokeUserRoleCommandHandler 创建命令处理器
+func NewRevokeUserRoleCommandHandler(
+	userRepo domain.UserRepository,
+	roleRepo domain.UserRoleRepository,
+) *RevokeUserRoleCommandHandler {
+	return &RevokeUserRoleCommandHandler{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+// Handle 处理撤销角色命令，未拥有该角色时不报错
+func (h *RevokeUserRoleCommandHandler) Handle(ctx context.Context, cmd RevokeUserRoleCommand) (err error) {
+	ctx, span := tracing.Start(ctx, "RevokeUserRoleCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	if err := validateAssignableRole(cmd.Role); err != nil {
+		return err
+	}
+	if err := ensureUserExists(ctx, h.userRepo, cmd.UserID); err != nil {
+		return err
+	}
+	return h.roleRepo.RemoveRole(ctx, cmd.UserID, cmd.Role)
+}
+
+// validateAssignableRole 校验角色可以被显式授予或撤销，RoleUser 为所有用户隐式拥有
+func validateAssignableRole(role domain.Role) error {
+	if !role.IsValid() || role == domain.RoleUser {
+		return domain.ErrInvalidRole
+	}
+	return nil
+}
+
+// ensureUserExists 确认用户存在且未被软删除
+func ensureUserExists(ctx context.Context, userRepo domain.UserRepository, userID string) error {
+	user, err := userRepo.FindById(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
@@ -4,46 +4,62 @@ import (
 	"context"
 
 	"go-protos/internal/domain"
+	"go-protos/pkg/tracing"
 
 	"github.com/google/uuid"
 )
 
 // CreateUserCommand 创建用户命令
 type CreateUserCommand struct {
-	Username     string
-	Email        string
-	PasswordHash string
+	Username string
+	Email    string
+	Password string
 }
 
 // CreateUserCommandHandler 创建用户命令处理器
 type CreateUserCommandHandler struct {
-	userRepo      domain.UserRepository
-	userDomainSvc *domain.UserDomainService
+	userRepo       domain.UserRepository
+	userDomainSvc  *domain.UserDomainService
+	passwordHasher domain.PasswordHasher
+	publisher      domain.UserEventPublisher
 }
 
 // NewCreateUserCommandHandler 创建命令处理器
 func NewCreateUserCommandHandler(
 	userRepo domain.UserRepository,
 	userDomainSvc *domain.UserDomainService,
+	passwordHasher domain.PasswordHasher,
+	publisher domain.UserEventPublisher,
 ) *CreateUserCommandHandler {
 	return &CreateUserCommandHandler{
-		userRepo:      userRepo,
-		userDomainSvc: userDomainSvc,
+		userRepo:       userRepo,
+		userDomainSvc:  userDomainSvc,
+		passwordHasher: passwordHasher,
+		publisher:      publisher,
 	}
 }
 
 // Handle 处理创建用户命令
-func (h *CreateUserCommandHandler) Handle(ctx context.Context, cmd CreateUserCommand) (*domain.User, error) {
+func (h *CreateUserCommandHandler) Handle(ctx context.Context, cmd CreateUserCommand) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "CreateUserCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
 	// 验证用户唯一性
 	if err := h.userDomainSvc.ValidateUserUniqueness(ctx, cmd.Username, cmd.Email); err != nil {
 		return nil, err
 	}
 
+	// 哈希密码
+	passwordHash, err := domain.HashPassword(h.passwordHasher, cmd.Password)
+	if err != nil {
+		return nil, err
+	}
+
 	// 生成ID
 	var id string = uuid.New().String()
 
 	// 创建用户实体
-	user, err := domain.NewUser(id, cmd.Username, cmd.Email, cmd.PasswordHash)
+	user, err := domain.NewUser(id, cmd.Username, cmd.Email, passwordHash)
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +69,9 @@ func (h *CreateUserCommandHandler) Handle(ctx context.Context, cmd CreateUserCom
 		return nil, err
 	}
 
+	// 发布事件
+	h.publisher.Publish(ctx, domain.NewUserEvent(domain.UserCreated, user, "username", "email"))
+
 	return user, nil
 }
 
@@ -66,21 +85,27 @@ type UpdateUserEmailCommand struct {
 type UpdateUserEmailCommandHandler struct {
 	userRepo      domain.UserRepository
 	userDomainSvc *domain.UserDomainService
+	publisher     domain.UserEventPublisher
 }
 
 // NewUpdateUserEmailCommandHandler 创建命令处理器
 func NewUpdateUserEmailCommandHandler(
 	userRepo domain.UserRepository,
 	userDomainSvc *domain.UserDomainService,
+	publisher domain.UserEventPublisher,
 ) *UpdateUserEmailCommandHandler {
 	return &UpdateUserEmailCommandHandler{
 		userRepo:      userRepo,
 		userDomainSvc: userDomainSvc,
+		publisher:     publisher,
 	}
 }
 
 // Handle 处理更新邮箱命令
-func (h *UpdateUserEmailCommandHandler) Handle(ctx context.Context, cmd UpdateUserEmailCommand) error {
+func (h *UpdateUserEmailCommandHandler) Handle(ctx context.Context, cmd UpdateUserEmailCommand) (err error) {
+	ctx, span := tracing.Start(ctx, "UpdateUserEmailCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
 	// 查找用户
 	user, err := h.userRepo.FindById(ctx, cmd.UserID)
 	if err != nil {
@@ -107,5 +132,231 @@ func (h *UpdateUserEmailCommandHandler) Handle(ctx context.Context, cmd UpdateUs
 	}
 
 	// 保存到仓储
-	return h.userRepo.Save(ctx, user)
+	if err := h.userRepo.Save(ctx, user); err != nil {
+		return err
+	}
+
+	// 发布事件
+	h.publisher.Publish(ctx, domain.NewUserEvent(domain.UserUpdated, user, "email"))
+	return nil
+}
+
+// UpdateUserPasswordCommand 更新用户密码命令
+type UpdateUserPasswordCommand struct {
+	UserID          string
+	CurrentPassword string
+	NewPassword     string
+}
+
+// UpdateUserPasswordCommandHandler 更新用户密码命令处理器
+type UpdateUserPasswordCommandHandler struct {
+	userRepo       domain.UserRepository
+	passwordHasher domain.PasswordHasher
+	publisher      domain.UserEventPublisher
+}
+
+// NewUpdateUserPasswordCommandHandler 创建命令处理器
+func NewUpdateUserPasswordCommandHandler(
+	userRepo domain.UserRepository,
+	passwordHasher domain.PasswordHasher,
+	publisher domain.UserEventPublisher,
+) *UpdateUserPasswordCommandHandler {
+	return &UpdateUserPasswordCommandHandler{
+		userRepo:       userRepo,
+		passwordHasher: passwordHasher,
+		publisher:      publisher,
+	}
+}
+
+// Handle 处理更新密码命令
+func (h *UpdateUserPasswordCommandHandler) Handle(ctx context.Context, cmd UpdateUserPasswordCommand) (err error) {
+	ctx, span := tracing.Start(ctx, "UpdateUserPasswordCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	// 查找用户
+	user, err := h.userRepo.FindById(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return domain.ErrUserNotFound
+	}
+
+	// 校验当前密码（新密码会整体替换哈希，无需关心是否触发了重新哈希）
+	if _, err := user.CheckPassword(h.passwordHasher, cmd.CurrentPassword); err != nil {
+		return err
+	}
+
+	// 更新密码
+	if err := user.ChangePassword(h.passwordHasher, cmd.NewPassword); err != nil {
+		return err
+	}
+
+	// 保存到仓储
+	if err := h.userRepo.Save(ctx, user); err != nil {
+		return err
+	}
+
+	// 发布事件（只通知字段变化，不携带密码内容）
+	h.publisher.Publish(ctx, domain.NewUserEvent(domain.UserUpdated, user, "password"))
+	return nil
+}
+
+// VerifyUserPasswordCommand 校验用户密码命令
+type VerifyUserPasswordCommand struct {
+	UserID   string
+	Password string
+}
+
+// VerifyUserPasswordCommandHandler 校验用户密码命令处理器
+// 校验成功且哈希参数已过时时，会透明地升级并持久化密码哈希
+type VerifyUserPasswordCommandHandler struct {
+	userRepo       domain.UserRepository
+	passwordHasher domain.PasswordHasher
+}
+
+// NewVerifyUserPasswordCommandHandler 创建命令处理器
+func NewVerifyUserPasswordCommandHandler(
+	userRepo domain.UserRepository,
+	passwordHasher domain.PasswordHasher,
+) *VerifyUserPasswordCommandHandler {
+	return &VerifyUserPasswordCommandHandler{
+		userRepo:       userRepo,
+		passwordHasher: passwordHasher,
+	}
+}
+
+// Handle 处理校验密码命令
+func (h *VerifyUserPasswordCommandHandler) Handle(ctx context.Context, cmd VerifyUserPasswordCommand) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "VerifyUserPasswordCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	// 查找用户
+	user, err := h.userRepo.FindById(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	// 校验密码
+	rehashed, err := user.CheckPassword(h.passwordHasher, cmd.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// 哈希已升级时持久化
+	if rehashed {
+		if err := h.userRepo.Save(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// UserField 可通过 UpdateUserCommand 更新的用户字段
+type UserField string
+
+const (
+	UserFieldUsername UserField = "username"
+	UserFieldEmail    UserField = "email"
+)
+
+// UpdateUserCommand 按字段部分更新用户命令，只应用 Fields 中列出的字段
+type UpdateUserCommand struct {
+	UserID   string
+	Username string
+	Email    string
+	Fields   []UserField
+}
+
+// UpdateUserCommandHandler 部分更新用户命令处理器
+type UpdateUserCommandHandler struct {
+	userRepo      domain.UserRepository
+	userDomainSvc *domain.UserDomainService
+	publisher     domain.UserEventPublisher
+}
+
+// NewUpdateUserCommandHandler 创建命令处理器
+func NewUpdateUserCommandHandler(
+	userRepo domain.UserRepository,
+	userDomainSvc *domain.UserDomainService,
+	publisher domain.UserEventPublisher,
+) *UpdateUserCommandHandler {
+	return &UpdateUserCommandHandler{
+		userRepo:      userRepo,
+		userDomainSvc: userDomainSvc,
+		publisher:     publisher,
+	}
+}
+
+// Handle 处理部分更新用户命令，返回更新后的用户
+func (h *UpdateUserCommandHandler) Handle(ctx context.Context, cmd UpdateUserCommand) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UpdateUserCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	if len(cmd.Fields) == 0 {
+		return nil, domain.ErrInvalidUpdateMask
+	}
+
+	// 查找用户
+	user, err := h.userRepo.FindById(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	// 逐个字段通过领域方法修改，值未变化的字段跳过
+	var changed []string
+	for _, field := range cmd.Fields {
+		switch field {
+		case UserFieldUsername:
+			if cmd.Username == user.Username {
+				continue
+			}
+			unique, err := h.userDomainSvc.IsUsernameUnique(ctx, cmd.Username)
+			if err != nil {
+				return nil, err
+			}
+			if !unique {
+				return nil, domain.ErrUsernameExists
+			}
+			if err := user.UpdateUsername(cmd.Username); err != nil {
+				return nil, err
+			}
+		case UserFieldEmail:
+			if cmd.Email == user.Email {
+				continue
+			}
+			unique, err := h.userDomainSvc.IsEmailUnique(ctx, cmd.Email)
+			if err != nil {
+				return nil, err
+			}
+			if !unique {
+				return nil, domain.ErrEmailExists
+			}
+			if err := user.UpdateEmail(cmd.Email); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, domain.ErrInvalidUpdateMask
+		}
+		changed = append(changed, string(field))
+	}
+	if len(changed) == 0 {
+		return user, nil
+	}
+
+	// 保存到仓储
+	if err := h.userRepo.Save(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// 发布事件
+	h.publisher.Publish(ctx, domain.NewUserEvent(domain.UserUpdated, user, changed...))
+	return user, nil
 }
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/eventbus"
+	"go-protos/internal/infrastructure/persistence/inmem"
+	"go-protos/internal/infrastructure/security/password"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHasher(t *testing.T, algorithm string) domain.PasswordHasher {
+	t.Helper()
+	hasher, err := password.New(password.Config{
+		Algorithm:  algorithm,
+		BcryptCost: 4,
+		Argon2:     password.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1},
+	})
+	require.NoError(t, err)
+	return hasher
+}
+
+func saveTestUser(t *testing.T, repo domain.UserRepository, hasher domain.PasswordHasher, plain string) *domain.User {
+	t.Helper()
+	hash, err := hasher.Hash(plain)
+	require.NoError(t, err)
+	user, err := domain.NewUser("u-1", "alice", "alice@example.com", hash)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(context.Background(), user))
+	return user
+}
+
+func TestUpdateUserPasswordCommandHandler(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewInMemoryUserRepository()
+	hasher := newTestHasher(t, password.AlgorithmBcrypt)
+	saveTestUser(t, repo, hasher, "old-password")
+
+	events := eventbus.NewUserEventLog(0)
+	handler := NewUpdateUserPasswordCommandHandler(repo, hasher, events)
+
+	// 当前密码不匹配
+	err := handler.Handle(ctx, UpdateUserPasswordCommand{
+		UserID:          "u-1",
+		CurrentPassword: "wrong-password",
+		NewPassword:     "new-password",
+	})
+	assert.ErrorIs(t, err, domain.ErrPasswordMismatch)
+
+	// 新密码不满足策略
+	err = handler.Handle(ctx, UpdateUserPasswordCommand{
+		UserID:          "u-1",
+		CurrentPassword: "old-password",
+		NewPassword:     "short",
+	})
+	assert.ErrorIs(t, err, domain.ErrWeakPassword)
+
+	// 正常更新
+	err = handler.Handle(ctx, UpdateUserPasswordCommand{
+		UserID:          "u-1",
+		CurrentPassword: "old-password",
+		NewPassword:     "new-password",
+	})
+	require.NoError(t, err)
+
+	saved, err := repo.FindById(ctx, "u-1")
+	require.NoError(t, err)
+	ok, err := hasher.Verify(saved.PasswordHash, "new-password")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// 仅成功的更新发布事件，且不携带密码哈希
+	published, err := events.Read(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, published, 1)
+	assert.Equal(t, domain.UserUpdated, published[0].Type)
+	assert.Equal(t, []string{"password"}, published[0].ChangedFields)
+	assert.Empty(t, published[0].User.PasswordHash)
+}
+
+func TestVerifyUserPasswordCommandHandlerRehash(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewInMemoryUserRepository()
+	user := saveTestUser(t, repo, newTestHasher(t, password.AlgorithmBcrypt), "s3cret-password")
+	bcryptHash := user.PasswordHash
+
+	// 切换到 argon2id 后，校验成功会透明升级哈希
+	handler := NewVerifyUserPasswordCommandHandler(repo, newTestHasher(t, password.AlgorithmArgon2id))
+
+	_, err := handler.Handle(ctx, VerifyUserPasswordCommand{UserID: "u-1", Password: "wrong-password"})
+	assert.ErrorIs(t, err, domain.ErrPasswordMismatch)
+
+	_, err = handler.Handle(ctx, VerifyUserPasswordCommand{UserID: "u-1", Password: "s3cret-password"})
+	require.NoError(t, err)
+
+	saved, err := repo.FindById(ctx, "u-1")
+	require.NoError(t, err)
+	assert.NotEqual(t, bcryptHash, saved.PasswordHash)
+	assert.Contains(t, saved.PasswordHash, "$argon2id$")
+}
+
+func TestUpdateUserCommandHandler(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewInMemoryUserRepository()
+	hasher := newTestHasher(t, password.AlgorithmBcrypt)
+	events := eventbus.NewUserEventLog(0)
+	saveTestUser(t, repo, hasher, "correct-horse")
+	handler := NewUpdateUserCommandHandler(repo, domain.NewUserDomainService(repo, domain.ReuseAfterPurge), events)
+
+	// 只应用掩码中的字段，未变化的字段不计入事件
+	user, err := handler.Handle(ctx, UpdateUserCommand{
+		UserID:   "u-1",
+		Username: "ignored",
+		Email:    "alice@example.com",
+		Fields:   []UserField{UserFieldEmail},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", user.Username)
+
+	user, err = handler.Handle(ctx, UpdateUserCommand{
+		UserID:   "u-1",
+		Username: "alice2",
+		Email:    "alice2@example.com",
+		Fields:   []UserField{UserFieldUsername, UserFieldEmail},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice2", user.Username)
+	assert.Equal(t, "alice2@example.com", user.Email)
+
+	batch, err := events.Read(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, []string{"username", "email"}, batch[0].ChangedFields)
+
+	_, err = handler.Handle(ctx, UpdateUserCommand{UserID: "u-1", Username: "x", Fields: []UserField{UserFieldUsername}})
+	assert.ErrorIs(t, err, domain.ErrInvalidUsername)
+
+	_, err = handler.Handle(ctx, UpdateUserCommand{UserID: "u-1"})
+	assert.ErrorIs(t, err, domain.ErrInvalidUpdateMask)
+}
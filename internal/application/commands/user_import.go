@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"context"
+
+	"go-protos/internal/domain"
+	"go-protos/pkg/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MaxImportBatchSize 单批导入的最大记录数
+const MaxImportBatchSize = 1000
+
+// ImportStatus 单条记录的导入结果
+type ImportStatus int
+
+const (
+	ImportCreated   ImportStatus = iota + 1 // 已创建（dry-run 时表示可以创建）
+	ImportDuplicate                         // 用户名或邮箱已存在
+	ImportInvalid                           // 记录校验失败
+)
+
+// ImportUserRecord 待导入的用户记录
+type ImportUserRecord struct {
+	Username string
+	Email    string
+	Password string
+}
+
+// ImportUserResult 单条记录的导入结果
+type ImportUserResult struct {
+	Index    int // 记录在整个导入流中的序号（从0开始）
+	Username string
+	Status   ImportStatus
+	UserID   string // 仅非 dry-run 的已创建记录有值
+	Err      error  // 重复或无效的原因，为领域错误
+}
+
+// ImportUsersSummary 导入汇总
+type ImportUsersSummary struct {
+	DryRun     bool
+	Created    int
+	Duplicates int
+	Invalid    int
+}
+
+// ImportUsersCommandHandler 批量导入用户命令处理器
+type ImportUsersCommandHandler struct {
+	userRepo       domain.UserRepository
+	userDomainSvc  *domain.UserDomainService
+	passwordHasher domain.PasswordHasher
+	publisher      domain.UserEventPublisher
+}
+
+// NewImportUsersCommandHandler 创建命令处理器
+func NewImportUsersCommandHandler(
+	userRepo domain.UserRepository,
+	userDomainSvc *domain.UserDomainService,
+	passwordHasher domain.PasswordHasher,
+	publisher domain.UserEventPublisher,
+) *ImportUsersCommandHandler {
+	return &ImportUsersCommandHandler{
+		userRepo:       userRepo,
+		userDomainSvc:  userDomainSvc,
+		passwordHasher: passwordHasher,
+		publisher:      publisher,
+	}
+}
+
+// NewSession 开始一次导入，一个导入流对应一个会话
+func (h *ImportUsersCommandHandler) NewSession(dryRun bool) *UserImportSession {
+	session := &UserImportSession{
+		handler: h,
+		summary: ImportUsersSummary{DryRun: dryRun},
+	}
+	if dryRun {
+		// dry-run 不落库，需要在会话内记住已接受的标识以检测跨批次重复
+		session.seenUsernames = make(map[string]bool)
+		session.seenEmails = make(map[string]bool)
+	}
+	return session
+}
+
+// UserImportSession 导入会话，按批处理记录并累计汇总
+type UserImportSession struct {
+	handler       *ImportUsersCommandHandler
+	next          int
+	seenUsernames map[string]bool
+	seenEmails    map[string]bool
+	summary       ImportUsersSummary
+}
+
+// Summary 返回当前汇总
+func (s *UserImportSession) Summary() ImportUsersSummary {
+	return s.summary
+}
+
+// importCandidate 通过校验、等待唯一性检查的记录
+type importCandidate struct {
+	result *ImportUserResult
+	user   *domain.User
+	record ImportUserRecord
+}
+
+// ImportBatch 处理一批记录：逐条校验、批量检查唯一性，非 dry-run 时在单个事务中插入
+func (s *UserImportSession) ImportBatch(ctx context.Context, records []ImportUserRecord) (_ []ImportUserResult, err error) {
+	ctx, span := tracing.Start(ctx, "UserImportSession.ImportBatch",
+		attribute.Int("import.batch_size", len(records)),
+		attribute.Bool("import.dry_run", s.summary.DryRun),
+	)
+	defer tracing.End(span, &err)
+
+	if len(records) > MaxImportBatchSize {
+		return nil, domain.ErrImportBatchTooLarge
+	}
+
+	results := make([]ImportUserResult, len(records))
+	candidates := make([]importCandidate, 0, len(records))
+	batchUsernames := make(map[string]bool, len(records))
+	batchEmails := make(map[string]bool, len(records))
+
+	// 逐条校验，并检测批次内（及 dry-run 会话内）的重复
+	for i, record := range records {
+		result := &results[i]
+		result.Index = s.next + i
+		result.Username = record.Username
+
+		user, err := domain.NewUser(uuid.New().String(), record.Username, record.Email, "pending")
+		if err == nil {
+			err = domain.ValidatePassword(record.Password)
+		}
+		if err != nil {
+			result.Status, result.Err = ImportInvalid, err
+			continue
+		}
+
+		if batchUsernames[user.Username] || s.seenUsernames[user.Username] {
+			result.Status, result.Err = ImportDuplicate, domain.ErrUsernameExists
+			continue
+		}
+		if user.Email != "" && (batchEmails[user.Email] || s.seenEmails[user.Email]) {
+			result.Status, result.Err = ImportDuplicate, domain.ErrEmailExists
+			continue
+		}
+		batchUsernames[user.Username] = true
+		if user.Email != "" {
+			batchEmails[user.Email] = true
+		}
+		candidates = append(candidates, importCandidate{result: result, user: user, record: record})
+	}
+	s.next += len(records)
+
+	// 批量检查与已有用户的冲突
+	usernames := make([]string, 0, len(candidates))
+	emails := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		usernames = append(usernames, c.user.Username)
+		if c.user.Email != "" {
+			emails = append(emails, c.user.Email)
+		}
+	}
+	takenUsernames, takenEmails, err := s.handler.userDomainSvc.FindTakenIdentifiers(ctx, usernames, emails)
+	if err != nil {
+		return nil, err
+	}
+
+	var accepted []importCandidate
+	for _, c := range candidates {
+		switch {
+		case takenUsernames[c.user.Username]:
+			c.result.Status, c.result.Err = ImportDuplicate, domain.ErrUsernameExists
+		case takenEmails[c.user.Email]:
+			c.result.Status, c.result.Err = ImportDuplicate, domain.ErrEmailExists
+		default:
+			accepted = append(accepted, c)
+		}
+	}
+
+	// 哈希密码并批量插入
+	if !s.summary.DryRun {
+		users := make([]*domain.User, 0, len(accepted))
+		for _, c := range accepted {
+			passwordHash, err := s.handler.passwordHasher.Hash(c.record.Password)
+			if err != nil {
+				return nil, err
+			}
+			c.user.PasswordHash = passwordHash
+			users = append(users, c.user)
+		}
+		if err := s.handler.userRepo.SaveBatch(ctx, users); err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			s.handler.publisher.Publish(ctx, domain.NewUserEvent(domain.UserCreated, user, "username", "email"))
+		}
+	}
+
+	for _, c := range accepted {
+		c.result.Status = ImportCreated
+		if s.summary.DryRun {
+			s.seenUsernames[c.user.Username] = true
+			if c.user.Email != "" {
+				s.seenEmails[c.user.Email] = true
+			}
+		} else {
+			c.result.UserID = c.user.ID
+		}
+	}
+
+	// 累计汇总
+	for _, r := range results {
+		switch r.Status {
+		case ImportCreated:
+			s.summary.Created++
+		case ImportDuplicate:
+			s.summary.Duplicates++
+		case ImportInvalid:
+			s.summary.Invalid++
+		}
+	}
+	return results, nil
+}
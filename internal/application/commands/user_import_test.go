@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/eventbus"
+	"go-protos/internal/infrastructure/persistence/inmem"
+	"go-protos/internal/infrastructure/security/password"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestImportHandler(t *testing.T) (*ImportUsersCommandHandler, *inmem.InMemoryUserRepository) {
+	t.Helper()
+	repo := inmem.NewInMemoryUserRepository()
+	hasher := newTestHasher(t, password.AlgorithmBcrypt)
+	saveTestUser(t, repo, hasher, "s3cret-password") // alice / alice@example.com
+	return NewImportUsersCommandHandler(repo, domain.NewUserDomainService(repo, domain.ReuseAfterPurge), hasher, eventbus.NewUserEventLog(0)), repo
+}
+
+func TestUserImportSession(t *testing.T) {
+	ctx := context.Background()
+	handler, repo := newTestImportHandler(t)
+	session := handler.NewSession(false)
+
+	results, err := session.ImportBatch(ctx, []ImportUserRecord{
+		{Username: "bob", Email: "bob@example.com", Password: "password-1"},
+		{Username: "alice", Email: "alice2@example.com", Password: "password-2"}, // 与已有用户重复
+		{Username: "carol", Email: "not-an-email", Password: "password-3"},       // 邮箱无效
+		{Username: "bob", Email: "bob2@example.com", Password: "password-4"},     // 批次内重复
+		{Username: "dave", Email: "dave@example.com", Password: "short"},         // 密码过短
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	assert.Equal(t, ImportCreated, results[0].Status)
+	assert.NotEmpty(t, results[0].UserID)
+	assert.Equal(t, ImportDuplicate, results[1].Status)
+	assert.ErrorIs(t, results[1].Err, domain.ErrUsernameExists)
+	assert.Equal(t, ImportInvalid, results[2].Status)
+	assert.ErrorIs(t, results[2].Err, domain.ErrInvalidEmail)
+	assert.Equal(t, ImportDuplicate, results[3].Status)
+	assert.Equal(t, ImportInvalid, results[4].Status)
+	assert.ErrorIs(t, results[4].Err, domain.ErrWeakPassword)
+
+	// 第二批：与上一批已写入的记录冲突，序号延续
+	results, err = session.ImportBatch(ctx, []ImportUserRecord{
+		{Username: "erin", Email: "bob@example.com", Password: "password-5"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, results[0].Index)
+	assert.ErrorIs(t, results[0].Err, domain.ErrEmailExists)
+
+	assert.Equal(t, ImportUsersSummary{Created: 1, Duplicates: 3, Invalid: 2}, session.Summary())
+
+	saved, err := repo.FindByUsernamesOrEmails(ctx, []string{"bob"}, nil, false)
+	require.NoError(t, err)
+	assert.Len(t, saved, 1)
+}
+
+func TestUserImportSessionDryRun(t *testing.T) {
+	ctx := context.Background()
+	handler, repo := newTestImportHandler(t)
+	session := handler.NewSession(true)
+
+	results, err := session.ImportBatch(ctx, []ImportUserRecord{
+		{Username: "bob", Email: "bob@example.com", Password: "password-1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ImportCreated, results[0].Status)
+	assert.Empty(t, results[0].UserID)
+
+	// dry-run 跨批次也能检测重复
+	results, err = session.ImportBatch(ctx, []ImportUserRecord{
+		{Username: "bob", Email: "bob2@example.com", Password: "password-2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ImportDuplicate, results[0].Status)
+
+	saved, err := repo.FindByUsernamesOrEmails(ctx, []string{"bob"}, nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, saved)
+	assert.Equal(t, ImportUsersSummary{DryRun: true, Created: 1, Duplicates: 1}, session.Summary())
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"context"
+
+	"go-protos/internal/domain"
+	"go-protos/pkg/tracing"
+)
+
+// DeleteUserCommand 软删除用户命令
+type DeleteUserCommand struct {
+	UserID string
+}
+
+// DeleteUserCommandHandler 软删除用户命令处理器
+type DeleteUserCommandHandler struct {
+	userRepo  domain.UserRepository
+	publisher domain.UserEventPublisher
+}
+
+// NewDeleteUserCommandHandler 创建命令处理器
+func NewDeleteUserCommandHandler(
+	userRepo domain.UserRepository,
+	publisher domain.UserEventPublisher,
+) *DeleteUserCommandHandler {
+	return &DeleteUserCommandHandler{
+		userRepo:  userRepo,
+		publisher: publisher,
+	}
+}
+
+// Handle 处理软删除用户命令
+func (h *DeleteUserCommandHandler) Handle(ctx context.Context, cmd DeleteUserCommand) (err error) {
+	ctx, span := tracing.Start(ctx, "DeleteUserCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	// 查找用户（已软删除的用户视为不存在）
+	user, err := h.userRepo.FindById(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return domain.ErrUserNotFound
+	}
+
+	// 写入删除墓碑
+	user.MarkDeleted()
+
+	// 保存到仓储
+	if err := h.userRepo.Save(ctx, user); err != nil {
+		return err
+	}
+
+	// 发布事件
+	h.publisher.Publish(ctx, domain.NewUserEvent(domain.UserDeleted, user, "deleted_at"))
+	return nil
+}
+
+// RestoreUserCommand 恢复用户命令
+type RestoreUserCommand struct {
+	UserID string
+}
+
+// RestoreUserCommandHandler 恢复用户命令处理器
+type RestoreUserCommandHandler struct {
+	userRepo      domain.UserRepository
+	userDomainSvc *domain.UserDomainService
+	publisher     domain.UserEventPublisher
+}
+
+// NewRestoreUserCommandHandler 创建命令处理器
+func NewRestoreUserCommandHandler(
+	userRepo domain.UserRepository,
+	userDomainSvc *domain.UserDomainService,
+	publisher domain.UserEventPublisher,
+) *RestoreUserCommandHandler {
+	return &RestoreUserCommandHandler{
+		userRepo:      userRepo,
+		userDomainSvc: userDomainSvc,
+		publisher:     publisher,
+	}
+}
+
+// Handle 处理恢复用户命令
+func (h *RestoreUserCommandHandler) Handle(ctx context.Context, cmd RestoreUserCommand) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "RestoreUserCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	// 查找用户（包含已软删除的用户），只有已删除的用户才能恢复
+	user, err := h.userRepo.FindByIdUnscoped(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsDeleted() {
+		return nil, domain.ErrUserNotFound
+	}
+
+	// 用户名、邮箱可能在删除期间已被复用
+	if err := h.userDomainSvc.ValidateRestorable(ctx, user); err != nil {
+		return nil, err
+	}
+
+	user.Restore()
+
+	// 保存到仓储
+	if err := h.userRepo.Save(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// 发布事件
+	h.publisher.Publish(ctx, domain.NewUserEvent(domain.UserUpdated, user, "deleted_at"))
+	return user, nil
+}
+
+// PurgeUserCommand 彻底清除用户命令
+type PurgeUserCommand struct {
+	UserID string
+}
+
+// PurgeUserCommandHandler 彻底清除用户命令处理器
+// 物理删除数据行，无论用户是否已软删除；清除后用户名和邮箱可被复用
+type PurgeUserCommandHandler struct {
+	userRepo  domain.UserRepository
+	publisher domain.UserEventPublisher
+}
+
+// NewPurgeUserCommandHandler 创建命令处理器
+func NewPurgeUserCommandHandler(
+	userRepo domain.UserRepository,
+	publisher domain.UserEventPublisher,
+) *PurgeUserCommandHandler {
+	return &PurgeUserCommandHandler{
+		userRepo:  userRepo,
+		publisher: publisher,
+	}
+}
+
+// Handle 处理彻底清除用户命令
+func (h *PurgeUserCommandHandler) Handle(ctx context.Context, cmd PurgeUserCommand) (err error) {
+	ctx, span := tracing.Start(ctx, "PurgeUserCommandHandler.Handle")
+	defer tracing.End(span, &err)
+
+	// 查找用户（包含已软删除的用户）
+	user, err := h.userRepo.FindByIdUnscoped(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return domain.ErrUserNotFound
+	}
+
+	// 物理删除
+	if err := h.userRepo.Purge(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// 已软删除的用户在删除时已发布过事件
+	if !user.IsDeleted() {
+		h.publisher.Publish(ctx, domain.NewUserEvent(domain.UserDeleted, user))
+	}
+	return nil
+}
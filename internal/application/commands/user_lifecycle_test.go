@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/eventbus"
+	"go-protos/internal/infrastructure/persistence/inmem"
+	"go-protos/internal/infrastructure/security/password"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserLifecycleCommandHandlers(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewInMemoryUserRepository()
+	hasher := newTestHasher(t, password.AlgorithmBcrypt)
+	events := eventbus.NewUserEventLog(0)
+	saveTestUser(t, repo, hasher, "correct-horse")
+
+	domainSvc := domain.NewUserDomainService(repo, domain.ReuseAfterPurge)
+	deleteHandler := NewDeleteUserCommandHandler(repo, events)
+	restoreHandler := NewRestoreUserCommandHandler(repo, domainSvc, events)
+	purgeHandler := NewPurgeUserCommandHandler(repo, events)
+
+	// 未删除的用户不能恢复
+	_, err := restoreHandler.Handle(ctx, RestoreUserCommand{UserID: "u-1"})
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	require.NoError(t, deleteHandler.Handle(ctx, DeleteUserCommand{UserID: "u-1"}))
+	_, err = repo.FindById(ctx, "u-1")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	assert.ErrorIs(t, deleteHandler.Handle(ctx, DeleteUserCommand{UserID: "u-1"}), domain.ErrUserNotFound)
+
+	// after_purge 策略下已删除用户仍占用用户名
+	unique, err := domainSvc.IsUsernameUnique(ctx, "alice")
+	require.NoError(t, err)
+	assert.False(t, unique)
+
+	restored, err := restoreHandler.Handle(ctx, RestoreUserCommand{UserID: "u-1"})
+	require.NoError(t, err)
+	assert.False(t, restored.IsDeleted())
+
+	require.NoError(t, purgeHandler.Handle(ctx, PurgeUserCommand{UserID: "u-1"}))
+	_, err = repo.FindByIdUnscoped(ctx, "u-1")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	batch, err := events.Read(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, batch, 3)
+	assert.Equal(t, domain.UserDeleted, batch[0].Type)
+	assert.Equal(t, domain.UserUpdated, batch[1].Type)
+	assert.Equal(t, domain.UserDeleted, batch[2].Type)
+}
+
+func TestRestoreUser_IdentifierReusedAfterDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewInMemoryUserRepository()
+	hasher := newTestHasher(t, password.AlgorithmBcrypt)
+	events := eventbus.NewUserEventLog(0)
+	saveTestUser(t, repo, hasher, "correct-horse")
+
+	domainSvc := domain.NewUserDomainService(repo, domain.ReuseAfterDelete)
+	require.NoError(t, NewDeleteUserCommandHandler(repo, events).Handle(ctx, DeleteUserCommand{UserID: "u-1"}))
+
+	// after_delete 策略下用户名可立即复用
+	created, err := NewCreateUserCommandHandler(repo, domainSvc, hasher, events).Handle(ctx, CreateUserCommand{
+		Username: "alice",
+		Email:    "alice2@example.com",
+		Password: "correct-horse",
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, "u-1", created.ID)
+
+	// 原用户恢复时用户名冲突
+	_, err = NewRestoreUserCommandHandler(repo, domainSvc, events).Handle(ctx, RestoreUserCommand{UserID: "u-1"})
+	assert.ErrorIs(t, err, domain.ErrUsernameExists)
+}
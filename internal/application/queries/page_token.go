@@ -0,0 +1,97 @@
+package queries
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go-protos/internal/domain"
+)
+
+// pageToken 不透明分页令牌的内容，编码为 base64url(JSON)
+type pageToken struct {
+	OrderBy   domain.UserOrderField `json:"o"`
+	Desc      bool                  `json:"d,omitempty"`
+	Filter    string                `json:"f"` // 过滤条件指纹，防止翻页时条件被篡改
+	CreatedAt int64                 `json:"c"` // UnixNano
+	Username  string                `json:"u,omitempty"`
+	ID        string                `json:"i"`
+}
+
+// parseOrderBy 解析排序表达式，如 "created_at"、"username desc"
+func parseOrderBy(orderBy string) (domain.UserOrderField, bool, error) {
+	fields := strings.Fields(strings.ToLower(orderBy))
+	if len(fields) == 0 {
+		return domain.OrderByCreatedAt, false, nil
+	}
+	if len(fields) > 2 {
+		return "", false, domain.ErrInvalidOrderBy
+	}
+
+	field := domain.UserOrderField(fields[0])
+	if field != domain.OrderByCreatedAt && field != domain.OrderByUsername {
+		return "", false, domain.ErrInvalidOrderBy
+	}
+
+	desc := false
+	if len(fields) == 2 {
+		switch fields[1] {
+		case "asc":
+		case "desc":
+			desc = true
+		default:
+			return "", false, domain.ErrInvalidOrderBy
+		}
+	}
+	return field, desc, nil
+}
+
+// filterFingerprint 计算过滤条件指纹
+func filterFingerprint(f domain.UserListFilter) string {
+	raw := strings.Join([]string{
+		f.CreatedAfter.UTC().Format(time.RFC3339Nano),
+		f.CreatedBefore.UTC().Format(time.RFC3339Nano),
+		f.EmailDomain,
+		f.UsernamePrefix,
+	}, "\x00")
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:8])
+}
+
+// encodePageToken 根据本页最后一条记录生成下一页令牌
+func encodePageToken(opts domain.UserListOptions, last *domain.User) string {
+	raw, _ := json.Marshal(pageToken{
+		OrderBy:   opts.OrderBy,
+		Desc:      opts.Desc,
+		Filter:    filterFingerprint(opts.Filter),
+		CreatedAt: last.CreatedAt.UnixNano(),
+		Username:  last.Username,
+		ID:        last.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodePageToken 解析分页令牌，并校验其与当前排序和过滤条件一致
+func decodePageToken(token string, opts domain.UserListOptions) (*domain.UserListCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, domain.ErrInvalidPageToken
+	}
+
+	var t pageToken
+	if err := json.Unmarshal(raw, &t); err != nil || t.ID == "" {
+		return nil, domain.ErrInvalidPageToken
+	}
+	if t.OrderBy != opts.OrderBy || t.Desc != opts.Desc || t.Filter != filterFingerprint(opts.Filter) {
+		return nil, domain.ErrInvalidPageToken
+	}
+
+	return &domain.UserListCursor{
+		CreatedAt: time.Unix(0, t.CreatedAt),
+		Username:  t.Username,
+		ID:        t.ID,
+	}, nil
+}
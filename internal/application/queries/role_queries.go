@@ -0,0 +1,47 @@
+package queries
+
+import (
+	"context"
+
+	"go-protos/internal/domain"
+	"go-protos/pkg/tracing"
+)
+
+// ListUserRolesQuery 查询用户角色
+type ListUserRolesQuery struct {
+	UserID string
+}
+
+// ListUserRolesQueryHandler 查询处理器
+type ListUserRolesQueryHandler struct {
+	userRepo domain.UserRepository
+	roleRepo domain.UserRoleRepository
+}
+
+// NewListUserRolesQueryHandler 创建查询处理器
+func NewListUserRolesQueryHandler(userRepo domain.UserRepository, roleRepo domain.UserRoleRepository) *ListUserRolesQueryHandler {
+	return &ListUserRolesQueryHandler{
+		userRepo: userRepo,
+		roleRepo: roleRepo,
+	}
+}
+
+// Handle 处理查询，返回的角色包含所有用户隐式拥有的 RoleUser
+func (h *ListUserRolesQueryHandler) Handle(ctx context.Context, query ListUserRolesQuery) (_ []domain.Role, err error) {
+	ctx, span := tracing.Start(ctx, "ListUserRolesQueryHandler.Handle")
+	defer tracing.End(span, &err)
+
+	user, err := h.userRepo.FindById(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	roles, err := h.roleRepo.ListRoles(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]domain.Role{domain.RoleUser}, roles...), nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"go-protos/internal/domain"
+	"go-protos/pkg/tracing"
 )
 
 // GetUserByIdQuery 根据ID查询用户
@@ -24,8 +25,18 @@ func NewGetUserByIdQueryHandler(userRepo domain.UserRepository) *GetUserByIdQuer
 }
 
 // Handle 处理查询
-func (h *GetUserByIdQueryHandler) Handle(ctx context.Context, query GetUserByIdQuery) (*domain.User, error) {
-	return h.userRepo.FindById(ctx, query.UserID)
+func (h *GetUserByIdQueryHandler) Handle(ctx context.Context, query GetUserByIdQuery) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "GetUserByIdQueryHandler.Handle")
+	defer tracing.End(span, &err)
+
+	user, err := h.userRepo.FindById(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
 }
 
 // GetUserByUsernameQuery 根据用户名查询用户
@@ -46,8 +57,18 @@ func NewGetUserByUsernameQueryHandler(userRepo domain.UserRepository) *GetUserBy
 }
 
 // Handle 处理查询
-func (h *GetUserByUsernameQueryHandler) Handle(ctx context.Context, query GetUserByUsernameQuery) (*domain.User, error) {
-	return h.userRepo.FindByUsername(ctx, query.Username)
+func (h *GetUserByUsernameQueryHandler) Handle(ctx context.Context, query GetUserByUsernameQuery) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "GetUserByUsernameQueryHandler.Handle")
+	defer tracing.End(span, &err)
+
+	user, err := h.userRepo.FindByUsername(ctx, query.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
 }
 
 // GetUserByEmailQuery 根据邮箱查询用户
@@ -68,6 +89,144 @@ func NewGetUserByEmailQueryHandler(userRepo domain.UserRepository) *GetUserByEma
 }
 
 // Handle 处理查询
-func (h *GetUserByEmailQueryHandler) Handle(ctx context.Context, query GetUserByEmailQuery) (*domain.User, error) {
-	return h.userRepo.FindByEmail(ctx, query.Email)
+func (h *GetUserByEmailQueryHandler) Handle(ctx context.Context, query GetUserByEmailQuery) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "GetUserByEmailQueryHandler.Handle")
+	defer tracing.End(span, &err)
+
+	user, err := h.userRepo.FindByEmail(ctx, query.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// 分页参数
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// ListUsersQuery 分页查询用户列表
+type ListUsersQuery struct {
+	PageSize  int    // 0 使用默认值，超过上限时按上限处理
+	PageToken string // 上一页返回的 NextPageToken
+	OrderBy   string // created_at / username，可追加 asc / desc
+	Filter    domain.UserListFilter
+}
+
+// ListUsersResult 用户列表查询结果
+type ListUsersResult struct {
+	Users         []*domain.User
+	NextPageToken string // 为空表示没有更多数据
+}
+
+// ListUsersQueryHandler 查询处理器
+type ListUsersQueryHandler struct {
+	userRepo domain.UserRepository
+}
+
+// NewListUsersQueryHandler 创建查询处理器
+func NewListUsersQueryHandler(userRepo domain.UserRepository) *ListUsersQueryHandler {
+	return &ListUsersQueryHandler{
+		userRepo: userRepo,
+	}
+}
+
+// Handle 处理查询
+func (h *ListUsersQueryHandler) Handle(ctx context.Context, query ListUsersQuery) (_ *ListUsersResult, err error) {
+	ctx, span := tracing.Start(ctx, "ListUsersQueryHandler.Handle")
+	defer tracing.End(span, &err)
+
+	// 校验分页大小
+	pageSize := query.PageSize
+	switch {
+	case pageSize < 0:
+		return nil, domain.ErrInvalidPageSize
+	case pageSize == 0:
+		pageSize = DefaultPageSize
+	case pageSize > MaxPageSize:
+		pageSize = MaxPageSize
+	}
+
+	// 解析排序
+	orderBy, desc, err := parseOrderBy(query.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := domain.UserListOptions{
+		Filter:  query.Filter,
+		OrderBy: orderBy,
+		Desc:    desc,
+		Limit:   pageSize + 1, // 多取一条用于判断是否还有下一页
+	}
+
+	// 解析分页令牌
+	if query.PageToken != "" {
+		cursor, err := decodePageToken(query.PageToken, opts)
+		if err != nil {
+			return nil, err
+		}
+		opts.After = cursor
+	}
+
+	users, err := h.userRepo.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListUsersResult{Users: users}
+	if len(users) > pageSize {
+		result.Users = users[:pageSize]
+		result.NextPageToken = encodePageToken(opts, result.Users[pageSize-1])
+	}
+	return result, nil
+}
+
+// watchBatchSize 单次从事件日志拉取的最大事件数
+const watchBatchSize = 100
+
+// WatchUsersQuery 订阅用户变更事件
+type WatchUsersQuery struct {
+	AfterSequence uint64 // 从该序号之后继续推送，0 表示只推送订阅之后的新事件
+}
+
+// WatchUsersQueryHandler 查询处理器
+type WatchUsersQueryHandler struct {
+	eventLog domain.UserEventLog
+}
+
+// NewWatchUsersQueryHandler 创建查询处理器
+func NewWatchUsersQueryHandler(eventLog domain.UserEventLog) *WatchUsersQueryHandler {
+	return &WatchUsersQueryHandler{
+		eventLog: eventLog,
+	}
+}
+
+// Handle 持续推送事件直到 ctx 结束或 send 返回错误
+// send 阻塞时不会继续拉取事件，由调用方的流控形成背压
+func (h *WatchUsersQueryHandler) Handle(ctx context.Context, query WatchUsersQuery, send func(domain.UserEvent) error) (err error) {
+	ctx, span := tracing.Start(ctx, "WatchUsersQueryHandler.Handle")
+	defer tracing.End(span, &err)
+
+	after := query.AfterSequence
+	if after == 0 {
+		after = h.eventLog.LastSequence()
+	}
+
+	for {
+		events, err := h.eventLog.Read(ctx, after, watchBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := send(event); err != nil {
+				return err
+			}
+			after = event.Sequence
+		}
+	}
 }
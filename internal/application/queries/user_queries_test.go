@@ -0,0 +1,62 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/persistence/inmem"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListUsersQueryHandlerPagination(t *testing.T) {
+	ctx := context.Background()
+	repo := inmem.NewInMemoryUserRepository()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		u, err := domain.NewUser(fmt.Sprintf("id-%d", i), fmt.Sprintf("user%d", i), fmt.Sprintf("user%d@example.com", i), "hash")
+		require.NoError(t, err)
+		u.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, repo.Save(ctx, u))
+	}
+
+	handler := NewListUsersQueryHandler(repo)
+
+	var got []string
+	query := ListUsersQuery{PageSize: 2, OrderBy: "created_at desc"}
+	for {
+		result, err := handler.Handle(ctx, query)
+		require.NoError(t, err)
+		for _, u := range result.Users {
+			got = append(got, u.ID)
+		}
+		if result.NextPageToken == "" {
+			break
+		}
+		query.PageToken = result.NextPageToken
+	}
+	assert.Equal(t, []string{"id-4", "id-3", "id-2", "id-1", "id-0"}, got)
+}
+
+func TestListUsersQueryHandlerValidation(t *testing.T) {
+	ctx := context.Background()
+	handler := NewListUsersQueryHandler(inmem.NewInMemoryUserRepository())
+
+	_, err := handler.Handle(ctx, ListUsersQuery{PageSize: -1})
+	assert.ErrorIs(t, err, domain.ErrInvalidPageSize)
+
+	_, err = handler.Handle(ctx, ListUsersQuery{OrderBy: "email"})
+	assert.ErrorIs(t, err, domain.ErrInvalidOrderBy)
+
+	_, err = handler.Handle(ctx, ListUsersQuery{PageToken: "not-a-token"})
+	assert.ErrorIs(t, err, domain.ErrInvalidPageToken)
+
+	// 令牌与排序条件不一致时拒绝
+	token := encodePageToken(domain.UserListOptions{OrderBy: domain.OrderByUsername}, &domain.User{ID: "id-1", Username: "user1"})
+	_, err = handler.Handle(ctx, ListUsersQuery{PageToken: token, OrderBy: "created_at"})
+	assert.ErrorIs(t, err, domain.ErrInvalidPageToken)
+}
@@ -9,42 +9,79 @@ import (
 )
 
 // UserAppService 用户应用服务
+// 每个命令和查询在分发前都会根据上下文中的调用方做权限检查
 type UserAppService struct {
+	accessPolicy *domain.AccessPolicy
+
 	// 命令处理器
-	createUserHandler      *commands.CreateUserCommandHandler
-	updateUserEmailHandler *commands.UpdateUserEmailCommandHandler
+	createUserHandler         *commands.CreateUserCommandHandler
+	updateUserEmailHandler    *commands.UpdateUserEmailCommandHandler
+	updateUserPasswordHandler *commands.UpdateUserPasswordCommandHandler
+	updateUserHandler         *commands.UpdateUserCommandHandler
+	verifyUserPasswordHandler *commands.VerifyUserPasswordCommandHandler
+	importUsersHandler        *commands.ImportUsersCommandHandler
+	deleteUserHandler         *commands.DeleteUserCommandHandler
+	restoreUserHandler        *commands.RestoreUserCommandHandler
+	purgeUserHandler          *commands.PurgeUserCommandHandler
+	grantUserRoleHandler      *commands.GrantUserRoleCommandHandler
+	revokeUserRoleHandler     *commands.RevokeUserRoleCommandHandler
 
 	// 查询处理器
 	getUserByIdHandler       *queries.GetUserByIdQueryHandler
 	getUserByUsernameHandler *queries.GetUserByUsernameQueryHandler
 	getUserByEmailHandler    *queries.GetUserByEmailQueryHandler
+	listUsersHandler         *queries.ListUsersQueryHandler
+	watchUsersHandler        *queries.WatchUsersQueryHandler
+	listUserRolesHandler     *queries.ListUserRolesQueryHandler
 }
 
 // NewUserAppService 创建用户应用服务
 func NewUserAppService(
 	userRepo domain.UserRepository,
+	roleRepo domain.UserRoleRepository,
 	userDomainSvc *domain.UserDomainService,
+	passwordHasher domain.PasswordHasher,
+	eventLog domain.UserEventLog,
 ) *UserAppService {
 	return &UserAppService{
-		createUserHandler:        commands.NewCreateUserCommandHandler(userRepo, userDomainSvc),
-		updateUserEmailHandler:   commands.NewUpdateUserEmailCommandHandler(userRepo, userDomainSvc),
-		getUserByIdHandler:       queries.NewGetUserByIdQueryHandler(userRepo),
-		getUserByUsernameHandler: queries.NewGetUserByUsernameQueryHandler(userRepo),
-		getUserByEmailHandler:    queries.NewGetUserByEmailQueryHandler(userRepo),
+		accessPolicy:              domain.NewAccessPolicy(roleRepo),
+		createUserHandler:         commands.NewCreateUserCommandHandler(userRepo, userDomainSvc, passwordHasher, eventLog),
+		updateUserEmailHandler:    commands.NewUpdateUserEmailCommandHandler(userRepo, userDomainSvc, eventLog),
+		updateUserPasswordHandler: commands.NewUpdateUserPasswordCommandHandler(userRepo, passwordHasher, eventLog),
+		updateUserHandler:         commands.NewUpdateUserCommandHandler(userRepo, userDomainSvc, eventLog),
+		verifyUserPasswordHandler: commands.NewVerifyUserPasswordCommandHandler(userRepo, passwordHasher),
+		importUsersHandler:        commands.NewImportUsersCommandHandler(userRepo, userDomainSvc, passwordHasher, eventLog),
+		deleteUserHandler:         commands.NewDeleteUserCommandHandler(userRepo, eventLog),
+		restoreUserHandler:        commands.NewRestoreUserCommandHandler(userRepo, userDomainSvc, eventLog),
+		purgeUserHandler:          commands.NewPurgeUserCommandHandler(userRepo, eventLog),
+		grantUserRoleHandler:      commands.NewGrantUserRoleCommandHandler(userRepo, roleRepo),
+		revokeUserRoleHandler:     commands.NewRevokeUserRoleCommandHandler(userRepo, roleRepo),
+		getUserByIdHandler:        queries.NewGetUserByIdQueryHandler(userRepo),
+		getUserByUsernameHandler:  queries.NewGetUserByUsernameQueryHandler(userRepo),
+		getUserByEmailHandler:     queries.NewGetUserByEmailQueryHandler(userRepo),
+		listUsersHandler:          queries.NewListUsersQueryHandler(userRepo),
+		watchUsersHandler:         queries.NewWatchUsersQueryHandler(eventLog),
+		listUserRolesHandler:      queries.NewListUserRolesQueryHandler(userRepo, roleRepo),
 	}
 }
 
 // 命令方法
-func (s *UserAppService) CreateUser(ctx context.Context, username, email, passwordHash string) (*domain.User, error) {
+func (s *UserAppService) CreateUser(ctx context.Context, username, email, password string) (*domain.User, error) {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersWrite, ""); err != nil {
+		return nil, err
+	}
 	cmd := commands.CreateUserCommand{
-		Username:     username,
-		Email:        email,
-		PasswordHash: passwordHash,
+		Username: username,
+		Email:    email,
+		Password: password,
 	}
 	return s.createUserHandler.Handle(ctx, cmd)
 }
 
 func (s *UserAppService) UpdateUserEmail(ctx context.Context, userID, email string) error {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersWrite, userID); err != nil {
+		return err
+	}
 	cmd := commands.UpdateUserEmailCommand{
 		UserID: userID,
 		Email:  email,
@@ -52,18 +89,139 @@ func (s *UserAppService) UpdateUserEmail(ctx context.Context, userID, email stri
 	return s.updateUserEmailHandler.Handle(ctx, cmd)
 }
 
+func (s *UserAppService) UpdateUserPassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersWrite, userID); err != nil {
+		return err
+	}
+	cmd := commands.UpdateUserPasswordCommand{
+		UserID:          userID,
+		CurrentPassword: currentPassword,
+		NewPassword:     newPassword,
+	}
+	return s.updateUserPasswordHandler.Handle(ctx, cmd)
+}
+
+func (s *UserAppService) UpdateUser(ctx context.Context, cmd commands.UpdateUserCommand) (*domain.User, error) {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersWrite, cmd.UserID); err != nil {
+		return nil, err
+	}
+	return s.updateUserHandler.Handle(ctx, cmd)
+}
+
+func (s *UserAppService) VerifyUserPassword(ctx context.Context, userID, password string) (*domain.User, error) {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersRead, userID); err != nil {
+		return nil, err
+	}
+	cmd := commands.VerifyUserPasswordCommand{
+		UserID:   userID,
+		Password: password,
+	}
+	return s.verifyUserPasswordHandler.Handle(ctx, cmd)
+}
+
+func (s *UserAppService) StartUserImport(ctx context.Context, dryRun bool) (*commands.UserImportSession, error) {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersImport, ""); err != nil {
+		return nil, err
+	}
+	return s.importUsersHandler.NewSession(dryRun), nil
+}
+
+func (s *UserAppService) DeleteUser(ctx context.Context, userID string) error {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersDelete, userID); err != nil {
+		return err
+	}
+	cmd := commands.DeleteUserCommand{UserID: userID}
+	return s.deleteUserHandler.Handle(ctx, cmd)
+}
+
+func (s *UserAppService) RestoreUser(ctx context.Context, userID string) (*domain.User, error) {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersDelete, userID); err != nil {
+		return nil, err
+	}
+	cmd := commands.RestoreUserCommand{UserID: userID}
+	return s.restoreUserHandler.Handle(ctx, cmd)
+}
+
+func (s *UserAppService) PurgeUser(ctx context.Context, userID string) error {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersPurge, userID); err != nil {
+		return err
+	}
+	cmd := commands.PurgeUserCommand{UserID: userID}
+	return s.purgeUserHandler.Handle(ctx, cmd)
+}
+
 // 查询方法
 func (s *UserAppService) GetUserById(ctx context.Context, id string) (*domain.User, error) {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersRead, id); err != nil {
+		return nil, err
+	}
 	query := queries.GetUserByIdQuery{UserID: id}
 	return s.getUserByIdHandler.Handle(ctx, query)
 }
 
 func (s *UserAppService) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
 	query := queries.GetUserByUsernameQuery{Username: username}
-	return s.getUserByUsernameHandler.Handle(ctx, query)
+	user, err := s.getUserByUsernameHandler.Handle(ctx, query)
+	return s.authorizeRead(ctx, user, err)
 }
 
 func (s *UserAppService) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := queries.GetUserByEmailQuery{Email: email}
-	return s.getUserByEmailHandler.Handle(ctx, query)
+	user, err := s.getUserByEmailHandler.Handle(ctx, query)
+	return s.authorizeRead(ctx, user, err)
+}
+
+func (s *UserAppService) ListUsers(ctx context.Context, query queries.ListUsersQuery) (*queries.ListUsersResult, error) {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersRead, ""); err != nil {
+		return nil, err
+	}
+	return s.listUsersHandler.Handle(ctx, query)
+}
+
+func (s *UserAppService) WatchUsers(ctx context.Context, afterSequence uint64, send func(domain.UserEvent) error) error {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersWatch, ""); err != nil {
+		return err
+	}
+	query := queries.WatchUsersQuery{AfterSequence: afterSequence}
+	return s.watchUsersHandler.Handle(ctx, query, send)
+}
+
+func (s *UserAppService) ListUserRoles(ctx context.Context, userID string) ([]domain.Role, error) {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersRead, userID); err != nil {
+		return nil, err
+	}
+	query := queries.ListUserRolesQuery{UserID: userID}
+	return s.listUserRolesHandler.Handle(ctx, query)
+}
+
+// 角色管理
+func (s *UserAppService) GrantUserRole(ctx context.Context, userID string, role domain.Role) error {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermRolesManage, userID); err != nil {
+		return err
+	}
+	cmd := commands.GrantUserRoleCommand{UserID: userID, Role: role}
+	return s.grantUserRoleHandler.Handle(ctx, cmd)
+}
+
+func (s *UserAppService) RevokeUserRole(ctx context.Context, userID string, role domain.Role) error {
+	if err := s.accessPolicy.Authorize(ctx, domain.PermRolesManage, userID); err != nil {
+		return err
+	}
+	cmd := commands.RevokeUserRoleCommand{UserID: userID, Role: role}
+	return s.revokeUserRoleHandler.Handle(ctx, cmd)
+}
+
+// authorizeRead 按用户名、邮箱查询时事先不知道记录归属，查到后再检查读取权限
+func (s *UserAppService) authorizeRead(ctx context.Context, user *domain.User, err error) (*domain.User, error) {
+	if err != nil {
+		if authzErr := s.accessPolicy.Authorize(ctx, domain.PermUsersRead, ""); authzErr != nil {
+			// 没有全局读取权限的调用方不应得知其他用户是否存在
+			return nil, authzErr
+		}
+		return nil, err
+	}
+	if err := s.accessPolicy.Authorize(ctx, domain.PermUsersRead, user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
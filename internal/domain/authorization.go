@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Role 用户角色
+type Role string
+
+const (
+	// RoleUser 自助用户，只能读取和修改自己的记录；所有用户隐式拥有该角色
+	RoleUser Role = "user"
+	// RoleAdmin 管理员，可以管理任意用户和角色
+	RoleAdmin Role = "admin"
+)
+
+// IsValid 是否为已知角色
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permission 操作权限，服务账号的API密钥 scopes 直接使用权限名
+type Permission string
+
+const (
+	PermUsersRead      Permission = "users:read"       // 读取任意用户及其角色、列表
+	PermUsersReadSelf  Permission = "users:read:self"  // 读取自己
+	PermUsersWrite     Permission = "users:write"      // 创建、修改任意用户
+	PermUsersWriteSelf Permission = "users:write:self" // 修改自己
+	PermUsersDelete    Permission = "users:delete"     // 软删除、恢复用户
+	PermUsersPurge     Permission = "users:purge"      // 彻底清除用户
+	PermUsersImport    Permission = "users:import"     // 批量导入用户
+	PermUsersWatch     Permission = "users:watch"      // 订阅用户变更事件
+	PermRolesManage    Permission = "roles:manage"     // 授予、撤销角色
+)
+
+// AllPermissions 所有权限
+var AllPermissions = []Permission{
+	PermUsersRead, PermUsersReadSelf, PermUsersWrite, PermUsersWriteSelf,
+	PermUsersDelete, PermUsersPurge, PermUsersImport, PermUsersWatch, PermRolesManage,
+}
+
+// rolePermissions 角色拥有的权限
+var rolePermissions = map[Role][]Permission{
+	RoleUser:  {PermUsersReadSelf, PermUsersWriteSelf},
+	RoleAdmin: AllPermissions,
+}
+
+// selfPermissions 全局权限对应的"仅限本人"权限
+var selfPermissions = map[Permission]Permission{
+	PermUsersRead:  PermUsersReadSelf,
+	PermUsersWrite: PermUsersWriteSelf,
+}
+
+// UserRole 用户与角色的关联
+type UserRole struct {
+	UserID    string    `gorm:"primaryKey;type:varchar(36);not null" json:"user_id"`
+	Role      Role      `gorm:"primaryKey;type:varchar(32);not null" json:"role"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定表名
+func (UserRole) TableName() string {
+	return "user_roles"
+}
+
+// UserRoleRepository 用户角色仓储
+type UserRoleRepository interface {
+	// ListRoles 返回用户被显式授予的角色（不含隐式的 RoleUser）
+	ListRoles(ctx context.Context, userID string) ([]Role, error)
+	// AddRole 授予角色，已拥有时不报错
+	AddRole(ctx context.Context, userID string, role Role) error
+	// RemoveRole 撤销角色，未拥有时不报错
+	RemoveRole(ctx context.Context, userID string, role Role) error
+}
+
+// AccessPolicy 访问控制策略：根据上下文中的调用方判断是否允许执行操作
+type AccessPolicy struct {
+	roleRepo UserRoleRepository
+}
+
+// NewAccessPolicy 创建访问控制策略
+func NewAccessPolicy(roleRepo UserRoleRepository) *AccessPolicy {
+	return &AccessPolicy{
+		roleRepo: roleRepo,
+	}
+}
+
+// Authorize 检查调用方是否拥有权限，ownerID 为操作涉及的用户ID（没有时为空）
+// 用户本人操作自己的记录时，拥有对应的"仅限本人"权限即可
+func (p *AccessPolicy) Authorize(ctx context.Context, perm Permission, ownerID string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return ErrPermissionDenied
+	}
+
+	granted, err := p.permissions(ctx, principal)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(granted, perm) {
+		return nil
+	}
+	if self, ok := selfPermissions[perm]; ok && ownerID != "" &&
+		principal.Type == PrincipalUser && principal.ID == ownerID && slices.Contains(granted, self) {
+		return nil
+	}
+	return ErrPermissionDenied
+}
+
+// permissions 计算调用方拥有的全部权限
+func (p *AccessPolicy) permissions(ctx context.Context, principal *Principal) ([]Permission, error) {
+	if principal.Type == PrincipalService {
+		granted := make([]Permission, 0, len(principal.Scopes))
+		for _, scope := range principal.Scopes {
+			granted = append(granted, Permission(scope))
+		}
+		return granted, nil
+	}
+
+	roles, err := p.roleRepo.ListRoles(ctx, principal.ID)
+	if err != nil {
+		return nil, err
+	}
+	granted := slices.Clone(rolePermissions[RoleUser])
+	for _, role := range roles {
+		granted = append(granted, rolePermissions[role]...)
+	}
+	return granted, nil
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"log/slog"
 	"regexp"
 	"strings"
 	"time"
@@ -11,14 +12,38 @@ import (
 
 // User 用户聚合根
 type User struct {
-	ID           string    `gorm:"primaryKey;type:varchar(36);not null" json:"id"`
-	Username     string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"`
-	Email        string    `gorm:"type:varchar(100);uniqueIndex" json:"email"`
-	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"` // 密码哈希不序列化
-	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID           string     `gorm:"primaryKey;type:varchar(36);not null" json:"id"`
+	Username     string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_users_username_deletion,priority:1" json:"username"`
+	Email        string     `gorm:"type:varchar(100);uniqueIndex:idx_users_email_deletion,priority:1" json:"email"`
+	PasswordHash string     `gorm:"type:varchar(255);not null" json:"-"` // 密码哈希不序列化
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt    *time.Time `gorm:"index" json:"-"` // 软删除时间，为空表示未删除；查询时的过滤由仓储实现
+	// DeletionKey 未删除时为空，软删除后为用户ID；与用户名、邮箱组成联合唯一索引，
+	// 使已删除用户不再占用数据库唯一约束，是否允许复用由 IdentifierReusePolicy 决定
+	DeletionKey string `gorm:"type:varchar(36);not null;default:'';uniqueIndex:idx_users_username_deletion,priority:2;uniqueIndex:idx_users_email_deletion,priority:2" json:"-"`
 }
 
+// LogValue 实现 slog.LogValuer，日志中只输出ID、用户名和邮箱，不输出密码哈希等其余字段；
+// 用户名和邮箱由日志脱敏按字段分类处理
+func (u *User) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", u.ID),
+		slog.String("username", u.Username),
+		slog.String("email", u.Email),
+	)
+}
+
+// IdentifierReusePolicy 已删除用户的用户名、邮箱复用策略
+type IdentifierReusePolicy string
+
+const (
+	// ReuseAfterPurge 软删除后仍保留用户名和邮箱，彻底清除后才可复用
+	ReuseAfterPurge IdentifierReusePolicy = "after_purge"
+	// ReuseAfterDelete 软删除后即可被新用户使用，恢复时若已被占用则失败
+	ReuseAfterDelete IdentifierReusePolicy = "after_delete"
+)
+
 // NewUser 创建新用户（工厂方法）
 func NewUser(id, username, email, passwordHash string) (*User, error) {
 	user := &User{
@@ -85,6 +110,16 @@ func (u *User) isValidEmail(email string) bool {
 	return emailRegex.MatchString(email)
 }
 
+// UpdateUsername 更新用户名
+func (u *User) UpdateUsername(username string) error {
+	if strings.TrimSpace(username) == "" || len(username) < 3 {
+		return ErrInvalidUsername
+	}
+	u.Username = username
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
 // UpdateEmail 更新邮箱
 func (u *User) UpdateEmail(email string) error {
 	if email != "" && !u.isValidEmail(email) {
@@ -105,11 +140,62 @@ func (u *User) UpdatePassword(passwordHash string) error {
 	return nil
 }
 
+// ChangePassword 使用密码哈希器设置新的明文密码
+func (u *User) ChangePassword(hasher PasswordHasher, password string) error {
+	passwordHash, err := HashPassword(hasher, password)
+	if err != nil {
+		return err
+	}
+	return u.UpdatePassword(passwordHash)
+}
+
+// CheckPassword 校验明文密码，匹配且哈希参数已过时时透明地重新生成哈希
+// 返回 rehashed=true 表示密码哈希已更新，调用方需要持久化
+func (u *User) CheckPassword(hasher PasswordHasher, password string) (rehashed bool, err error) {
+	ok, err := hasher.Verify(u.PasswordHash, password)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, ErrPasswordMismatch
+	}
+
+	if !hasher.NeedsRehash(u.PasswordHash) {
+		return false, nil
+	}
+	passwordHash, err := hasher.Hash(password)
+	if err != nil {
+		return false, err
+	}
+	u.PasswordHash = passwordHash
+	return true, nil
+}
+
 // IsValid 检查用户是否有效
 func (u *User) IsValid() bool {
 	return u.validate() == nil
 }
 
+// MarkDeleted 软删除用户
+func (u *User) MarkDeleted() {
+	now := time.Now()
+	u.DeletedAt = &now
+	u.DeletionKey = u.ID
+	u.UpdatedAt = now
+}
+
+// Restore 恢复已软删除的用户
+func (u *User) Restore() {
+	u.DeletedAt = nil
+	u.DeletionKey = ""
+	u.UpdatedAt = time.Now()
+}
+
+// IsDeleted 是否已软删除
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
 // TableName 指定表名
 func (User) TableName() string {
 	return "users_test"
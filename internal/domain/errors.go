@@ -1,12 +1,61 @@
 package domain
 
-import "errors"
+// ErrorKind 领域错误分类，接口层据此映射为协议状态码
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindNotFound
+	KindAlreadyExists
+	KindInvalidArgument
+	KindOutOfRange
+	KindUnauthenticated
+	KindPermissionDenied
+)
+
+// Error 领域错误
+type Error struct {
+	Kind   ErrorKind
+	Reason string // 稳定的错误原因码，客户端可据此分支处理
+	Field  string // 关联字段，校验类错误使用
+	msg    string
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Expected 未找到是查询的正常结果（如唯一性检查），链路追踪不将其记为错误
+func (e *Error) Expected() bool {
+	return e.Kind == KindNotFound
+}
+
+func newError(kind ErrorKind, reason, field, msg string) *Error {
+	return &Error{Kind: kind, Reason: reason, Field: field, msg: msg}
+}
 
 var (
-	ErrUserNotFound    = errors.New("user not found")
-	ErrUsernameExists  = errors.New("username already exists")
-	ErrEmailExists     = errors.New("email already exists")
-	ErrInvalidEmail    = errors.New("invalid email format")
-	ErrInvalidUsername = errors.New("invalid username")
-	ErrInvalidPassword = errors.New("invalid password")
+	ErrUserNotFound        = newError(KindNotFound, "USER_NOT_FOUND", "", "user not found")
+	ErrUsernameExists      = newError(KindAlreadyExists, "USERNAME_EXISTS", "username", "username already exists")
+	ErrEmailExists         = newError(KindAlreadyExists, "EMAIL_EXISTS", "email", "email already exists")
+	ErrInvalidEmail        = newError(KindInvalidArgument, "INVALID_EMAIL", "email", "invalid email format")
+	ErrInvalidUsername     = newError(KindInvalidArgument, "INVALID_USERNAME", "username", "invalid username")
+	ErrInvalidPassword     = newError(KindInvalidArgument, "INVALID_PASSWORD", "password", "invalid password")
+	ErrWeakPassword        = newError(KindInvalidArgument, "WEAK_PASSWORD", "password", "password must be at least 8 characters")
+	ErrPasswordMismatch    = newError(KindInvalidArgument, "PASSWORD_MISMATCH", "current_password", "current password does not match")
+	ErrInvalidPageSize     = newError(KindInvalidArgument, "INVALID_PAGE_SIZE", "page_size", "page size must not be negative")
+	ErrInvalidPageToken    = newError(KindInvalidArgument, "INVALID_PAGE_TOKEN", "page_token", "invalid or expired page token")
+	ErrImportBatchTooLarge = newError(KindInvalidArgument, "IMPORT_BATCH_TOO_LARGE", "users", "import batch must not exceed 1000 records")
+	ErrEventsExpired       = newError(KindOutOfRange, "EVENTS_EXPIRED", "after_sequence", "requested events are no longer retained, resync required")
+	ErrInvalidOrderBy      = newError(KindInvalidArgument, "INVALID_ORDER_BY", "order_by", "order by must be created_at or username, optionally followed by asc or desc")
+	ErrInvalidUpdateMask   = newError(KindInvalidArgument, "INVALID_UPDATE_MASK", "update_mask", "update mask contains no updatable fields")
+	ErrSessionNotFound     = newError(KindNotFound, "SESSION_NOT_FOUND", "", "session not found")
+	ErrInvalidCredentials  = newError(KindUnauthenticated, "INVALID_CREDENTIALS", "", "invalid username, email or password")
+	ErrInvalidRefreshToken = newError(KindUnauthenticated, "INVALID_REFRESH_TOKEN", "refresh_token", "refresh token is invalid, expired or revoked")
+	ErrMissingCredentials  = newError(KindUnauthenticated, "MISSING_CREDENTIALS", "", "missing bearer token or api key")
+	ErrInvalidAccessToken  = newError(KindUnauthenticated, "INVALID_ACCESS_TOKEN", "", "access token is invalid or expired")
+	ErrInvalidAPIKey       = newError(KindUnauthenticated, "INVALID_API_KEY", "", "invalid api key")
+	ErrPermissionDenied    = newError(KindPermissionDenied, "PERMISSION_DENIED", "", "permission denied")
+	ErrInvalidRole         = newError(KindInvalidArgument, "INVALID_ROLE", "role", "unknown role or role cannot be assigned")
 )
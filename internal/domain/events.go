@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// UserEventType 用户变更事件类型
+type UserEventType int
+
+const (
+	UserCreated UserEventType = iota + 1
+	UserUpdated
+	UserDeleted
+)
+
+// UserEvent 用户变更事件
+type UserEvent struct {
+	Sequence      uint64 // 由事件日志分配，单调递增
+	Type          UserEventType
+	User          User     // 变更后的用户快照（不含密码哈希）
+	ChangedFields []string // 发生变化的字段
+	OccurredAt    time.Time
+}
+
+// NewUserEvent 根据已提交的用户状态创建事件
+func NewUserEvent(eventType UserEventType, user *User, changedFields ...string) UserEvent {
+	snapshot := *user
+	snapshot.PasswordHash = ""
+	return UserEvent{
+		Type:          eventType,
+		User:          snapshot,
+		ChangedFields: changedFields,
+		OccurredAt:    time.Now(),
+	}
+}
+
+// UserEventPublisher 用户事件发布端口
+// Publish 不得阻塞写入方，事件在仓储提交成功后发布
+type UserEventPublisher interface {
+	Publish(ctx context.Context, event UserEvent)
+}
+
+// UserEventLog 可按序号回放的用户事件日志
+type UserEventLog interface {
+	UserEventPublisher
+	// Read 返回序号大于 after 的事件（最多 limit 条）
+	// 暂无新事件时阻塞直到有新事件或 ctx 结束；after 早于日志保留范围时返回 ErrEventsExpired
+	Read(ctx context.Context, after uint64, limit int) ([]UserEvent, error)
+	// LastSequence 返回最新事件的序号
+	LastSequence() uint64
+}
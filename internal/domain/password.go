@@ -0,0 +1,35 @@
+package domain
+
+import "strings"
+
+// MinPasswordLength 明文密码最小长度
+const MinPasswordLength = 8
+
+// PasswordHasher 密码哈希端口，具体算法由基础设施层实现
+type PasswordHasher interface {
+	// Hash 生成明文密码的哈希
+	Hash(password string) (string, error)
+	// Verify 校验明文密码与哈希是否匹配
+	Verify(hash, password string) (bool, error)
+	// NeedsRehash 判断哈希是否需要按当前算法和参数重新生成
+	NeedsRehash(hash string) bool
+}
+
+// ValidatePassword 校验明文密码是否满足密码策略
+func ValidatePassword(password string) error {
+	if strings.TrimSpace(password) == "" {
+		return ErrInvalidPassword
+	}
+	if len(password) < MinPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// HashPassword 校验并哈希明文密码
+func HashPassword(hasher PasswordHasher, password string) (string, error) {
+	if err := ValidatePassword(password); err != nil {
+		return "", err
+	}
+	return hasher.Hash(password)
+}
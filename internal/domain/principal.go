@@ -0,0 +1,35 @@
+package domain
+
+import "context"
+
+// PrincipalType 调用方类型
+type PrincipalType string
+
+const (
+	// PrincipalUser 通过访问令牌认证的终端用户
+	PrincipalUser PrincipalType = "user"
+	// PrincipalService 通过API密钥认证的服务账号
+	PrincipalService PrincipalType = "service"
+)
+
+// Principal 已认证的调用方
+type Principal struct {
+	Type      PrincipalType
+	ID        string   // 用户ID或服务账号名
+	Name      string   // 用户名或服务账号名，仅用于展示和日志
+	SessionID string   // 用户会话ID，服务账号为空
+	Scopes    []string // 服务账号被授予的权限范围
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal 将调用方写入上下文
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, p)
+}
+
+// PrincipalFromContext 从上下文读取调用方，未认证时返回 false
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -1,10 +1,63 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
+// UserRepository 用户仓储，除特别说明外查询均不返回已软删除的用户
 type UserRepository interface {
 	FindByUsername(ctx context.Context, username string) (*User, error)
 	FindById(ctx context.Context, id string) (*User, error)
 	FindByEmail(ctx context.Context, email string) (*User, error)
+	// FindByIdUnscoped 根据ID查找用户，包含已软删除的用户
+	FindByIdUnscoped(ctx context.Context, id string) (*User, error)
+	// Save 保存用户（包括软删除和恢复后的状态）
 	Save(ctx context.Context, user *User) error
+	List(ctx context.Context, opts UserListOptions) ([]*User, error)
+	// FindByUsernamesOrEmails 批量查找用户名或邮箱命中任一给定值的用户
+	// includeDeleted 为 true 时包含已软删除的用户
+	FindByUsernamesOrEmails(ctx context.Context, usernames, emails []string, includeDeleted bool) ([]*User, error)
+	// SaveBatch 在同一事务中批量插入用户
+	SaveBatch(ctx context.Context, users []*User) error
+	// Purge 物理删除用户记录，不存在时返回 ErrUserNotFound
+	Purge(ctx context.Context, id string) error
+}
+
+// UserOrderField 用户列表排序字段
+type UserOrderField string
+
+const (
+	OrderByCreatedAt UserOrderField = "created_at"
+	OrderByUsername  UserOrderField = "username"
+)
+
+// UserListFilter 用户列表过滤条件，零值表示不过滤
+type UserListFilter struct {
+	CreatedAfter   time.Time // 创建时间下界（包含）
+	CreatedBefore  time.Time // 创建时间上界（不包含）
+	EmailDomain    string    // 邮箱域名，如 example.com
+	UsernamePrefix string    // 用户名前缀
+}
+
+// UserListCursor 键集分页游标，记录上一页最后一条记录的排序键
+type UserListCursor struct {
+	CreatedAt time.Time
+	Username  string
+	ID        string
+}
+
+// UserListOptions 用户列表查询选项
+// 结果按 OrderBy 排序，并以 ID 作为次级排序键保证顺序稳定
+type UserListOptions struct {
+	Filter  UserListFilter
+	OrderBy UserOrderField
+	Desc    bool
+	After   *UserListCursor // 为空时从第一条开始
+	Limit   int
+}
+
+// CursorOf 返回指向指定用户之后的游标
+func CursorOf(u *User) *UserListCursor {
+	return &UserListCursor{CreatedAt: u.CreatedAt, Username: u.Username, ID: u.ID}
 }
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// refreshTokenSecretBytes 刷新令牌随机部分的字节数
+const refreshTokenSecretBytes = 32
+
+// Session 登录会话，每个会话对应一个刷新令牌
+type Session struct {
+	ID     string `gorm:"primaryKey;type:varchar(36);not null" json:"id"`
+	UserID string `gorm:"type:varchar(36);not null;index" json:"user_id"`
+	// RefreshTokenHash 刷新令牌随机部分的 SHA-256，明文令牌只在签发时返回给客户端
+	RefreshTokenHash string `gorm:"type:char(64);not null" json:"-"`
+	// PreviousRefreshTokenHash 上一次轮换前的刷新令牌哈希，再次出现说明令牌可能已泄露
+	PreviousRefreshTokenHash string     `gorm:"type:char(64);not null;default:''" json:"-"`
+	ExpiresAt                time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedAt                *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt                time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt                time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// NewSession 创建会话并生成刷新令牌，返回的 refreshToken 需要交给客户端
+func NewSession(id, userID string, ttl time.Duration) (session *Session, refreshToken string, err error) {
+	now := time.Now()
+	session = &Session{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	refreshToken, err = session.Rotate(ttl)
+	if err != nil {
+		return nil, "", err
+	}
+	return session, refreshToken, nil
+}
+
+// Rotate 轮换刷新令牌并顺延过期时间，旧令牌随即失效
+func (s *Session) Rotate(ttl time.Duration) (refreshToken string, err error) {
+	secret := make([]byte, refreshTokenSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+
+	now := time.Now()
+	s.PreviousRefreshTokenHash = s.RefreshTokenHash
+	s.RefreshTokenHash = hashRefreshSecret(encoded)
+	s.ExpiresAt = now.Add(ttl)
+	s.UpdatedAt = now
+	return s.ID + "." + encoded, nil
+}
+
+// MatchesRefreshToken 以常量时间比较刷新令牌的随机部分
+func (s *Session) MatchesRefreshToken(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(s.RefreshTokenHash), []byte(hashRefreshSecret(secret))) == 1
+}
+
+// MatchesPreviousRefreshToken 判断是否为上一次轮换前的刷新令牌
+func (s *Session) MatchesPreviousRefreshToken(secret string) bool {
+	return s.PreviousRefreshTokenHash != "" &&
+		subtle.ConstantTimeCompare([]byte(s.PreviousRefreshTokenHash), []byte(hashRefreshSecret(secret))) == 1
+}
+
+// IsActive 会话未撤销且未过期
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Revoke 撤销会话，重复撤销保持首次撤销时间
+func (s *Session) Revoke() {
+	if s.RevokedAt != nil {
+		return
+	}
+	now := time.Now()
+	s.RevokedAt = &now
+	s.UpdatedAt = now
+}
+
+// TableName 指定表名
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// ParseRefreshToken 将刷新令牌拆分为会话ID和随机部分
+func ParseRefreshToken(token string) (sessionID, secret string, err error) {
+	sessionID, secret, ok := strings.Cut(token, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return "", "", ErrInvalidRefreshToken
+	}
+	return sessionID, secret, nil
+}
+
+// hashRefreshSecret 计算刷新令牌随机部分的哈希（随机部分熵足够，无需慢哈希）
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// SessionRepository 会话仓储
+type SessionRepository interface {
+	Save(ctx context.Context, session *Session) error
+	// SaveRotated 保存轮换后的会话，仅当存储中的刷新令牌仍是 PreviousRefreshTokenHash 且会话未撤销时生效，
+	// 否则返回 ErrInvalidRefreshToken；并发刷新同一令牌时只有一方成功
+	SaveRotated(ctx context.Context, session *Session) error
+	// FindById 会话不存在时返回 ErrSessionNotFound
+	FindById(ctx context.Context, id string) (*Session, error)
+}
+
+// TokenIssuer 访问令牌签发端口，具体格式和签名算法由基础设施层实现
+type TokenIssuer interface {
+	// IssueAccessToken 为用户的某个会话签发短期访问令牌
+	IssueAccessToken(user *User, sessionID string) (token string, expiresAt time.Time, err error)
+}
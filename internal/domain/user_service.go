@@ -6,23 +6,33 @@ import (
 
 // UserDomainService 用户领域服务
 type UserDomainService struct {
-	userRepo UserRepository
+	userRepo    UserRepository
+	reusePolicy IdentifierReusePolicy
 }
 
 // NewUserDomainService 创建用户领域服务
-func NewUserDomainService(userRepo UserRepository) *UserDomainService {
+func NewUserDomainService(userRepo UserRepository, reusePolicy IdentifierReusePolicy) *UserDomainService {
+	if reusePolicy == "" {
+		reusePolicy = ReuseAfterPurge
+	}
 	return &UserDomainService{
-		userRepo: userRepo,
+		userRepo:    userRepo,
+		reusePolicy: reusePolicy,
 	}
 }
 
+// includeDeleted 唯一性检查是否需要考虑已软删除的用户
+func (s *UserDomainService) includeDeleted() bool {
+	return s.reusePolicy != ReuseAfterDelete
+}
+
 // IsUsernameUnique 检查用户名是否唯一
 func (s *UserDomainService) IsUsernameUnique(ctx context.Context, username string) (bool, error) {
-	existing, err := s.userRepo.FindByUsername(ctx, username)
+	existing, err := s.userRepo.FindByUsernamesOrEmails(ctx, []string{username}, nil, s.includeDeleted())
 	if err != nil {
 		return false, err
 	}
-	return existing == nil, nil
+	return len(existing) == 0, nil
 }
 
 // IsEmailUnique 检查邮箱是否唯一
@@ -30,11 +40,11 @@ func (s *UserDomainService) IsEmailUnique(ctx context.Context, email string) (bo
 	if email == "" {
 		return true, nil
 	}
-	existing, err := s.userRepo.FindByEmail(ctx, email)
+	existing, err := s.userRepo.FindByUsernamesOrEmails(ctx, nil, []string{email}, s.includeDeleted())
 	if err != nil {
 		return false, err
 	}
-	return existing == nil, nil
+	return len(existing) == 0, nil
 }
 
 // ValidateUserUniqueness 验证用户唯一性
@@ -61,3 +71,46 @@ func (s *UserDomainService) ValidateUserUniqueness(ctx context.Context, username
 
 	return nil
 }
+
+// FindTakenIdentifiers 批量检查用户名和邮箱，返回已被占用的用户名和邮箱集合
+func (s *UserDomainService) FindTakenIdentifiers(ctx context.Context, usernames, emails []string) (map[string]bool, map[string]bool, error) {
+	takenUsernames := make(map[string]bool)
+	takenEmails := make(map[string]bool)
+	if len(usernames) == 0 && len(emails) == 0 {
+		return takenUsernames, takenEmails, nil
+	}
+
+	existing, err := s.userRepo.FindByUsernamesOrEmails(ctx, usernames, emails, s.includeDeleted())
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, user := range existing {
+		takenUsernames[user.Username] = true
+		if user.Email != "" {
+			takenEmails[user.Email] = true
+		}
+	}
+	return takenUsernames, takenEmails, nil
+}
+
+// ValidateRestorable 检查已删除用户的用户名和邮箱在恢复前未被其他有效用户占用
+func (s *UserDomainService) ValidateRestorable(ctx context.Context, user *User) error {
+	var emails []string
+	if user.Email != "" {
+		emails = []string{user.Email}
+	}
+	existing, err := s.userRepo.FindByUsernamesOrEmails(ctx, []string{user.Username}, emails, false)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID == user.ID {
+			continue
+		}
+		if other.Username == user.Username {
+			return ErrUsernameExists
+		}
+		return ErrEmailExists
+	}
+	return nil
+}
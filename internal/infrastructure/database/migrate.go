@@ -2,7 +2,7 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 
 	"go-protos/internal/domain"
 
@@ -11,23 +11,47 @@ import (
 
 // Migrate 执行数据库迁移
 func Migrate(db *gorm.DB) error {
-	log.Println("Starting database migration...")
+	slog.Info("starting database migration")
+
+	// 用户名、邮箱的单列唯一索引已改为与 deletion_key 的联合唯一索引
+	if err := dropLegacyUserIndexes(db); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
 
 	// 迁移所有表
 	if err := db.AutoMigrate(
 		&domain.User{},
+		&domain.Session{},
+		&domain.UserRole{},
 		// 在这里添加其他实体
 	); err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
-	log.Println("Database migration completed successfully")
+	slog.Info("database migration completed")
+	return nil
+}
+
+// dropLegacyUserIndexes 删除旧版本的用户名、邮箱单列唯一索引
+func dropLegacyUserIndexes(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&domain.User{}) {
+		return nil
+	}
+	for _, index := range []string{"idx_users_test_username", "idx_users_test_email"} {
+		if !migrator.HasIndex(&domain.User{}, index) {
+			continue
+		}
+		if err := migrator.DropIndex(&domain.User{}, index); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // MigrateWithLog 带详细日志的迁移
 func MigrateWithLog(db *gorm.DB) error {
-	log.Println("Starting database migration...")
+	slog.Info("starting database migration")
 
 	// 检查表是否存在
 	var tables []string
@@ -35,7 +59,7 @@ func MigrateWithLog(db *gorm.DB) error {
 		return fmt.Errorf("failed to check existing tables: %w", err)
 	}
 
-	log.Printf("Existing tables: %v", tables)
+	slog.Info("existing tables", slog.Any("tables", tables))
 
 	// 执行迁移
 	if err := Migrate(db); err != nil {
@@ -47,6 +71,6 @@ func MigrateWithLog(db *gorm.DB) error {
 		return fmt.Errorf("failed to check tables after migration: %w", err)
 	}
 
-	log.Printf("Tables after migration: %v", tables)
+	slog.Info("tables after migration", slog.Any("tables", tables))
 	return nil
 }
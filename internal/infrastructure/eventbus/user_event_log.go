@@ -0,0 +1,105 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+
+	"go-protos/internal/domain"
+)
+
+// DefaultCapacity 默认保留的事件数量
+const DefaultCapacity = 1024
+
+// UserEventLog 基于环形缓冲区的内存用户事件日志
+//
+// 写入方只追加事件并唤醒等待者，从不等待订阅方；每个订阅方按自己的序号拉取，
+// 发送速度由各自的 gRPC 流控决定，因此慢消费者不会阻塞写入方。
+// 落后超过缓冲区容量的订阅方会收到 domain.ErrEventsExpired，需要重新同步。
+// 序号仅在当前进程内有效，重启后从1重新开始。
+type UserEventLog struct {
+	mu       sync.Mutex
+	events   []domain.UserEvent // 环形缓冲区
+	lastSeq  uint64
+	notifyCh chan struct{} // 有新事件时关闭并替换
+}
+
+// NewUserEventLog 创建事件日志
+func NewUserEventLog(capacity int) *UserEventLog {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &UserEventLog{
+		events:   make([]domain.UserEvent, capacity),
+		notifyCh: make(chan struct{}),
+	}
+}
+
+// Publish 追加事件并分配序号
+func (l *UserEventLog) Publish(ctx context.Context, event domain.UserEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastSeq++
+	event.Sequence = l.lastSeq
+	l.events[l.index(event.Sequence)] = event
+
+	close(l.notifyCh)
+	l.notifyCh = make(chan struct{})
+}
+
+// Read 读取序号大于 after 的事件
+func (l *UserEventLog) Read(ctx context.Context, after uint64, limit int) ([]domain.UserEvent, error) {
+	for {
+		l.mu.Lock()
+		if after > l.lastSeq || after+1 < l.oldestSeq() {
+			l.mu.Unlock()
+			return nil, domain.ErrEventsExpired
+		}
+		if after < l.lastSeq {
+			events := l.copyFrom(after+1, limit)
+			l.mu.Unlock()
+			return events, nil
+		}
+		notify := l.notifyCh
+		l.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// LastSequence 返回最新事件的序号
+func (l *UserEventLog) LastSequence() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastSeq
+}
+
+// oldestSeq 返回缓冲区中最早事件的序号
+func (l *UserEventLog) oldestSeq() uint64 {
+	capacity := uint64(len(l.events))
+	if l.lastSeq <= capacity {
+		return 1
+	}
+	return l.lastSeq - capacity + 1
+}
+
+// copyFrom 复制从 seq 开始的最多 limit 条事件
+func (l *UserEventLog) copyFrom(seq uint64, limit int) []domain.UserEvent {
+	n := l.lastSeq - seq + 1
+	if limit > 0 && n > uint64(limit) {
+		n = uint64(limit)
+	}
+	events := make([]domain.UserEvent, 0, n)
+	for i := uint64(0); i < n; i++ {
+		events = append(events, l.events[l.index(seq+i)])
+	}
+	return events
+}
+
+func (l *UserEventLog) index(seq uint64) int {
+	return int((seq - 1) % uint64(len(l.events)))
+}
@@ -0,0 +1,70 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-protos/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publishN(log *UserEventLog, n int) {
+	for i := 0; i < n; i++ {
+		log.Publish(context.Background(), domain.NewUserEvent(domain.UserUpdated, &domain.User{ID: "u-1"}, "email"))
+	}
+}
+
+func TestUserEventLogReadAndResume(t *testing.T) {
+	log := NewUserEventLog(4)
+	publishN(log, 3)
+
+	events, err := log.Read(context.Background(), 0, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, uint64(1), events[0].Sequence)
+	assert.Equal(t, uint64(2), events[1].Sequence)
+
+	// 从上次收到的序号继续
+	events, err = log.Read(context.Background(), 2, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, uint64(3), events[0].Sequence)
+}
+
+func TestUserEventLogBlocksUntilPublish(t *testing.T) {
+	log := NewUserEventLog(4)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		publishN(log, 1)
+	}()
+
+	events, err := log.Read(context.Background(), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = log.Read(ctx, 1, 10)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestUserEventLogExpired(t *testing.T) {
+	log := NewUserEventLog(2)
+	publishN(log, 5)
+
+	// 序号 1、2 已被覆盖
+	_, err := log.Read(context.Background(), 1, 10)
+	assert.ErrorIs(t, err, domain.ErrEventsExpired)
+
+	events, err := log.Read(context.Background(), 3, 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	// 来自其他进程的未来序号
+	_, err = log.Read(context.Background(), 99, 10)
+	assert.ErrorIs(t, err, domain.ErrEventsExpired)
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"go-protos/internal/domain"
+	"go-protos/pkg/requestid"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type scopeKey struct{}
+
+// requestScope 请求级日志作用域，保存在请求处理过程中才确定的字段
+type requestScope struct {
+	mu        sync.Mutex
+	principal *domain.Principal
+}
+
+// WithRequestScope 为请求创建日志作用域，在请求入口（早于访问日志）调用
+// 作用域让外层日志（如访问日志）也能带上之后认证得到的调用方
+func WithRequestScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &requestScope{})
+}
+
+// SetPrincipal 将认证后的调用方记录到请求作用域，没有作用域时忽略
+func SetPrincipal(ctx context.Context, principal *domain.Principal) {
+	scope, ok := ctx.Value(scopeKey{}).(*requestScope)
+	if !ok {
+		return
+	}
+	scope.mu.Lock()
+	scope.principal = principal
+	scope.mu.Unlock()
+}
+
+// contextAttrs 从上下文提取请求ID、trace ID、span ID 和调用方
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	var attrs []slog.Attr
+	if id := requestid.FromContext(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs,
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	if principal := principalFromContext(ctx); principal != nil {
+		attrs = append(attrs, slog.Group("principal",
+			slog.String("type", string(principal.Type)),
+			slog.String("id", principal.ID),
+		))
+	}
+	return attrs
+}
+
+// principalFromContext 优先使用上下文中的调用方，其次使用请求作用域中记录的调用方
+func principalFromContext(ctx context.Context) *domain.Principal {
+	if principal, ok := domain.PrincipalFromContext(ctx); ok {
+		return principal
+	}
+	scope, ok := ctx.Value(scopeKey{}).(*requestScope)
+	if !ok {
+		return nil
+	}
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	return scope.principal
+}
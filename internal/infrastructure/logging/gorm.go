@@ -0,0 +1,21 @@
+package logging
+
+import (
+	"log/slog"
+	"time"
+
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold 超过该耗时的语句以 warn 级别记录
+const slowQueryThreshold = 200 * time.Millisecond
+
+// NewGORMLogger 将 GORM 的慢查询和错误日志输出到 slog，语句只记录占位符不记录参数值
+func NewGORMLogger(logger *slog.Logger) gormlogger.Interface {
+	return gormlogger.NewSlogLogger(logger, gormlogger.Config{
+		SlowThreshold:             slowQueryThreshold,
+		LogLevel:                  gormlogger.Warn,
+		IgnoreRecordNotFoundError: true,
+		ParameterizedQueries:      true,
+	})
+}
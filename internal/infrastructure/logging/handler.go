@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// packageLevel 按包覆盖的日志级别
+type packageLevel struct {
+	pkg   string
+	level slog.Level
+}
+
+// handler 按调用方所在包过滤级别，并从上下文附加请求相关字段
+type handler struct {
+	next  slog.Handler
+	state *state // WithAttrs、WithGroup 派生的处理器共享，Reconfigure 修改后同时生效
+}
+
+// state 可在运行时调整的设置
+type state struct {
+	levels     atomic.Pointer[levels]
+	minLevel   slog.LevelVar // 底层处理器使用的级别
+	unredacted atomic.Bool
+}
+
+// levels 一组生效的级别，调整时整体替换
+type levels struct {
+	level    slog.Level     // 默认级别
+	minLevel slog.Level     // 默认级别与各包级别中的最低值
+	packages []packageLevel // 按包路径长度降序，优先匹配更具体的包
+	byPC     sync.Map       // 调用位置 PC -> 生效的级别
+}
+
+// newHandler 创建处理器，调用方随后设置 next
+func newHandler(level slog.Level, packages []packageLevel) *handler {
+	h := &handler{state: &state{}}
+	h.setLevels(level, packages)
+	return h
+}
+
+// setLevels 替换默认级别和按包级别
+func (h *handler) setLevels(level slog.Level, packages []packageLevel) {
+	sort.Slice(packages, func(i, j int) bool { return len(packages[i].pkg) > len(packages[j].pkg) })
+	minLevel := level
+	for _, p := range packages {
+		minLevel = min(minLevel, p.level)
+	}
+	h.state.levels.Store(&levels{level: level, minLevel: minLevel, packages: packages})
+	h.state.minLevel.Set(minLevel)
+}
+
+// replaceAttr 脱敏开启时按字段分类处理属性
+func (h *handler) replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if h.state.unredacted.Load() {
+		return a
+	}
+	return redactAttr(groups, a)
+}
+
+// Enabled 调用位置在 Handle 中才可知，这里只排除低于所有级别的记录
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.state.levels.Load().minLevel
+}
+
+// Handle 过滤级别后附加上下文字段
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.state.levels.Load().levelFor(r.PC) {
+		return nil
+	}
+	r.AddAttrs(contextAttrs(ctx)...)
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs 实现 slog.Handler
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithAttrs(attrs)
+	return &clone
+}
+
+// WithGroup 实现 slog.Handler
+func (h *handler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	return &clone
+}
+
+// levelFor 返回调用位置所在包生效的级别
+func (l *levels) levelFor(pc uintptr) slog.Level {
+	if len(l.packages) == 0 || pc == 0 {
+		return l.level
+	}
+	if level, ok := l.byPC.Load(pc); ok {
+		return level.(slog.Level)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	level := l.level
+	pkg := packagePath(frame.Function)
+	for _, p := range l.packages {
+		if matchPackage(pkg, p.pkg) {
+			level = p.level
+			break
+		}
+	}
+	l.byPC.Store(pc, level)
+	return level
+}
+
+// packagePath 从函数全名（如 "go-protos/internal/interfaces/grpc.(*Server).Start"）中取出包路径
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// matchPackage 判断包或其父包的导入路径是否等于 key 或以 "/"+key 结尾
+func matchPackage(pkg, key string) bool {
+	for p := pkg; p != ""; {
+		if p == key || strings.HasSuffix(p, "/"+key) {
+			return true
+		}
+		slash := strings.LastIndex(p, "/")
+		if slash < 0 {
+			break
+		}
+		p = p[:slash]
+	}
+	return false
+}
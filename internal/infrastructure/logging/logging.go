@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// 支持的输出格式
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// 支持的输出目标
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+)
+
+// Config 日志配置
+type Config struct {
+	Level  string // debug / info / warn / error
+	Format string // json / text
+	Output string // stdout / stderr / file
+	// 以下仅 Output 为 file 时使用
+	Filename       string
+	MaxSizeMB      int           // 单个文件超过该大小时轮转，0 使用默认值 100MB
+	MaxBackups     int           // 保留的历史文件数，0 表示全部保留
+	MaxAgeDays     int           // 历史文件保留天数，0 表示不按时间清理
+	Compress       bool          // 是否 gzip 压缩历史文件
+	RotateInterval time.Duration // 按时间轮转的间隔（按 UTC 对齐，如 24h 在零点轮转），0 表示只按大小轮转
+	// Unredacted 关闭日志脱敏，仅用于非生产环境排查问题
+	Unredacted bool
+	// Packages 按包覆盖日志级别，键为包的导入路径或其结尾部分（如 "internal/interfaces/grpc"、"gorm.io/gorm"），
+	// 同时作用于子包，多个匹配时取最长的
+	Packages map[string]string
+}
+
+// New 根据配置创建日志记录器，返回的 Closer 在退出前关闭日志文件
+// 记录器会从上下文中自动附加请求ID、trace ID 和调用方，并按字段分类脱敏个人信息和凭证
+func New(cfg Config) (*slog.Logger, io.Closer, error) {
+	level, packages, err := parseLevels(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.Format != FormatJSON && cfg.Format != FormatText && cfg.Format != "" {
+		return nil, nil, fmt.Errorf("unsupported log format %q", cfg.Format)
+	}
+	out, closer, err := newOutput(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := newHandler(level, packages)
+	h.state.unredacted.Store(cfg.Unredacted)
+	// 底层处理器只排除低于所有级别的记录，按包的级别由 handler 判断
+	opts := &slog.HandlerOptions{Level: &h.state.minLevel, ReplaceAttr: h.replaceAttr}
+	if cfg.Format == FormatText {
+		h.next = slog.NewTextHandler(out, opts)
+	} else {
+		h.next = slog.NewJSONHandler(out, opts)
+	}
+	return slog.New(h), closer, nil
+}
+
+// Reconfigure 在运行时调整由 New 创建的记录器的级别、按包级别和脱敏开关，
+// 输出目标、格式和轮转设置需要重启后生效；配置不合法时保持原有设置
+func Reconfigure(logger *slog.Logger, cfg Config) error {
+	h, ok := logger.Handler().(*handler)
+	if !ok {
+		return fmt.Errorf("logger was not created by logging.New")
+	}
+	level, packages, err := parseLevels(cfg)
+	if err != nil {
+		return err
+	}
+	h.setLevels(level, packages)
+	h.state.unredacted.Store(cfg.Unredacted)
+	return nil
+}
+
+// parseLevels 解析默认级别和按包级别
+func parseLevels(cfg Config) (slog.Level, []packageLevel, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return 0, nil, err
+	}
+	packages := make([]packageLevel, 0, len(cfg.Packages))
+	for pkg, value := range cfg.Packages {
+		pkgLevel, err := ParseLevel(value)
+		if err != nil {
+			return 0, nil, fmt.Errorf("package %s: %w", pkg, err)
+		}
+		packages = append(packages, packageLevel{pkg: strings.Trim(pkg, "/"), level: pkgLevel})
+	}
+	return level, packages, nil
+}
+
+// ParseLevel 解析日志级别名称，空字符串视为 info
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", name)
+	}
+	return level, nil
+}
+
+// newOutput 按配置打开输出目标
+func newOutput(cfg Config) (io.Writer, io.Closer, error) {
+	switch cfg.Output {
+	case OutputStdout, "":
+		return os.Stdout, nopCloser{}, nil
+	case OutputStderr:
+		return os.Stderr, nopCloser{}, nil
+	case OutputFile:
+		if cfg.Filename == "" {
+			return nil, nil, fmt.Errorf("log filename is required for file output")
+		}
+		file := &lumberjack.Logger{
+			Filename:   cfg.Filename,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+			LocalTime:  true,
+		}
+		if cfg.RotateInterval > 0 {
+			w := newTimedRotator(file, cfg.RotateInterval, time.Now)
+			return w, file, nil
+		}
+		return file, file, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported log output %q", cfg.Output)
+	}
+}
+
+// nopCloser 标准输出无需关闭
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,169 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-protos/internal/domain"
+	"go-protos/pkg/requestid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// newTestLogger 创建输出 JSON 到缓冲区的记录器
+func newTestLogger(level slog.Level, packages ...packageLevel) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	h := newHandler(level, packages)
+	h.next = slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: &h.state.minLevel})
+	return slog.New(h), &buf
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var line map[string]any
+		require.NoError(t, dec.Decode(&line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestHandler_ContextAttrs(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+
+	ctx := WithRequestScope(requestid.NewContext(context.Background(), "req-1"))
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	// 认证发生在内层，外层持有的上下文通过请求作用域拿到调用方
+	SetPrincipal(ctx, &domain.Principal{Type: domain.PrincipalUser, ID: "u-1"})
+	logger.InfoContext(ctx, "access")
+	logger.Info("no context")
+
+	lines := decodeLines(t, buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "req-1", lines[0]["request_id"])
+	assert.Equal(t, traceID.String(), lines[0]["trace_id"])
+	assert.Equal(t, spanID.String(), lines[0]["span_id"])
+	assert.Equal(t, map[string]any{"type": "user", "id": "u-1"}, lines[0]["principal"])
+	assert.NotContains(t, lines[1], "request_id")
+}
+
+func TestHandler_PackageLevels(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelWarn,
+		packageLevel{pkg: "infrastructure/logging", level: slog.LevelDebug},
+		packageLevel{pkg: "gorm.io/gorm", level: slog.LevelError},
+	)
+
+	// 本测试位于 internal/infrastructure/logging 包，按包级别输出 debug
+	logger.Debug("debug from logging package")
+	assert.Len(t, decodeLines(t, buf), 1)
+
+	// 其他包使用默认级别
+	other, buf := newTestLogger(slog.LevelWarn, packageLevel{pkg: "interfaces/grpc", level: slog.LevelDebug})
+	other.Info("info from logging package")
+	assert.Empty(t, decodeLines(t, buf))
+}
+
+func TestMatchPackage(t *testing.T) {
+	assert.Equal(t, "go-protos/internal/interfaces/grpc", packagePath("go-protos/internal/interfaces/grpc.(*Server).Start.func1"))
+	assert.Equal(t, "gorm.io/gorm/logger", packagePath("gorm.io/gorm/logger.(*slogLogger).Trace"))
+
+	assert.True(t, matchPackage("go-protos/internal/interfaces/grpc", "internal/interfaces/grpc"))
+	assert.True(t, matchPackage("go-protos/internal/interfaces/grpc", "go-protos/internal"))
+	assert.True(t, matchPackage("gorm.io/gorm/logger", "gorm.io/gorm"))
+	assert.False(t, matchPackage("go-protos/internal/interfaces/grpc", "pc"))
+	assert.False(t, matchPackage("go-protos/internal/interfaces/http", "interfaces/grpc"))
+}
+
+func TestTimedRotator(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 1, 1, 23, 59, 0, 0, time.UTC)
+	file := &lumberjack.Logger{Filename: filepath.Join(dir, "app.log")}
+	t.Cleanup(func() { file.Close() })
+	w := newTimedRotator(file, 24*time.Hour, func() time.Time { return now })
+
+	_, err := w.Write([]byte("day one\n"))
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	_, err = w.Write([]byte("day two\n"))
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "crossing midnight rotates the file")
+	current, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "day two\n", string(current))
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	_, _, err := New(Config{Level: "verbose"})
+	assert.Error(t, err)
+	_, _, err = New(Config{Format: "xml"})
+	assert.Error(t, err)
+	_, _, err = New(Config{Output: "file"})
+	assert.Error(t, err)
+	_, _, err = New(Config{Packages: map[string]string{"gorm.io/gorm": "loud"}})
+	assert.Error(t, err)
+}
+
+func TestRedactAttr(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redactAttr}))
+
+	logger.Info("login failed for alice@example.com",
+		slog.String("email", "alice@example.com"),
+		slog.String("username", "alice"),
+		slog.String("password", "hunter2"),
+		slog.Any("error", errors.New("duplicate entry 'bob@example.com'")),
+		slog.String("user_id", "u-1"),
+	)
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	line := lines[0]
+	assert.Equal(t, "login failed for a***@example.com", line["msg"])
+	assert.Equal(t, "a***@example.com", line["email"])
+	assert.Equal(t, "a***", line["username"])
+	assert.Equal(t, "[REDACTED]", line["password"])
+	assert.Equal(t, "duplicate entry 'b***@example.com'", line["error"])
+	assert.Equal(t, "u-1", line["user_id"])
+}
+
+func TestReconfigure(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+	child := logger.With(slog.String("component", "test"))
+
+	child.Debug("dropped")
+	assert.Empty(t, decodeLines(t, buf))
+
+	// 派生的记录器同样生效
+	require.NoError(t, Reconfigure(logger, Config{Level: "debug"}))
+	child.Debug("kept")
+	assert.Len(t, decodeLines(t, buf), 1)
+
+	// 不合法的配置保持原有级别
+	assert.Error(t, Reconfigure(logger, Config{Level: "verbose"}))
+	child.Debug("still kept")
+	assert.Len(t, decodeLines(t, buf), 1)
+
+	require.NoError(t, Reconfigure(logger, Config{Level: "warn", Packages: map[string]string{"infrastructure/logging": "error"}}))
+	child.Warn("dropped by package level")
+	assert.Empty(t, decodeLines(t, buf))
+
+	assert.Error(t, Reconfigure(slog.New(slog.NewTextHandler(buf, nil)), Config{}))
+}
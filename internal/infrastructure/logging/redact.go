@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+
+	"go-protos/pkg/redact"
+)
+
+// redactAttr 按 redact 的字段分类脱敏日志属性（包括消息本身），作为 slog.HandlerOptions.ReplaceAttr 使用
+// 未登记的字符串字段和错误也会清理其中夹带的邮箱和令牌
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	class := redact.Classify(a.Key)
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(redact.Value(class, a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(redact.Value(class, v.Error()))
+		case []string:
+			redacted := make([]string, len(v))
+			for i, s := range v {
+				redacted[i] = redact.Value(class, s)
+			}
+			a.Value = slog.AnyValue(redacted)
+		case fmt.Stringer:
+			if class != redact.Public {
+				a.Value = slog.StringValue(redact.Value(class, v.String()))
+			}
+		default:
+			if class != redact.Public {
+				a.Value = slog.StringValue(redact.Placeholder)
+			}
+		}
+	default:
+		if class != redact.Public {
+			a.Value = slog.StringValue(redact.Placeholder)
+		}
+	}
+	return a
+}
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// timedRotator 在 lumberjack 按大小轮转的基础上，每跨过一个时间边界强制轮转一次
+type timedRotator struct {
+	mu       sync.Mutex
+	file     *lumberjack.Logger
+	interval time.Duration
+	next     time.Time
+	now      func() time.Time
+}
+
+func newTimedRotator(file *lumberjack.Logger, interval time.Duration, now func() time.Time) *timedRotator {
+	return &timedRotator{
+		file:     file,
+		interval: interval,
+		next:     now().Truncate(interval).Add(interval),
+		now:      now,
+	}
+}
+
+// Write 写入前检查是否已跨过轮转边界
+func (w *timedRotator) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if now := w.now(); !now.Before(w.next) {
+		w.next = now.Truncate(w.interval).Add(w.interval)
+		if err := w.file.Rotate(); err != nil {
+			return 0, err
+		}
+	}
+	return w.file.Write(p)
+}
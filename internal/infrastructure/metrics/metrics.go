@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-protos/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics 服务的 Prometheus 指标
+// 使用独立的 Registry 而不是全局默认注册表，便于在测试中重复创建
+type Metrics struct {
+	registry *prometheus.Registry
+
+	grpcHandled  *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	dbQueries    *prometheus.HistogramVec
+	userEvents   *prometheus.CounterVec
+	idgenWaits   prometheus.Counter
+	idgenWaited  prometheus.Counter
+}
+
+// New 创建并注册所有指标，同时注册 Go 运行时和进程指标
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		grpcHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_handled_total",
+			Help: "Total number of RPCs completed on the server, by method and status code.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_handling_seconds",
+			Help:    "Latency of RPCs handled by the server, by method and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_server_requests_total",
+			Help: "Total number of HTTP requests, by method, route pattern and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_server_request_duration_seconds",
+			Help:    "Latency of HTTP requests, by method, route pattern and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Latency of GORM statements, by operation, table and outcome.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table", "status"}),
+		userEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "user_events_total",
+			Help: "Total number of user change events published, by type (created, updated, deleted).",
+		}, []string{"type"}),
+		idgenWaits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "idgen_sequence_overflow_waits_total",
+			Help: "Number of times the ID generator exhausted its per-millisecond sequence and waited for the next millisecond.",
+		}),
+		idgenWaited: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "idgen_sequence_overflow_wait_seconds_total",
+			Help: "Total time the ID generator spent waiting after sequence overflow.",
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.grpcHandled, m.grpcDuration,
+		m.httpRequests, m.httpDuration,
+		m.dbQueries,
+		m.userEvents,
+		m.idgenWaits, m.idgenWaited,
+	)
+	return m
+}
+
+// Handler 返回 /metrics 的处理器
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterDBStats 注册连接池指标（打开、使用中、空闲连接数，等待次数和时长等）
+func (m *Metrics) RegisterDBStats(db *sql.DB, dbName string) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// ObserveGRPC 记录一次RPC
+func (m *Metrics) ObserveGRPC(method, code string, duration time.Duration) {
+	m.grpcHandled.WithLabelValues(method, code).Inc()
+	m.grpcDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
+
+// ObserveHTTP 记录一次HTTP请求，route 使用路由模式而不是实际路径，避免标签基数失控
+func (m *Metrics) ObserveHTTP(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveIDGenOverflow 记录ID生成器序列号溢出后的等待，作为 idgen.WithOverflowObserver 的回调
+func (m *Metrics) ObserveIDGenOverflow(wait time.Duration) {
+	m.idgenWaits.Inc()
+	m.idgenWaited.Add(wait.Seconds())
+}
+
+// InstrumentUserEventLog 包装用户事件日志，发布事件时计数
+func (m *Metrics) InstrumentUserEventLog(log domain.UserEventLog) domain.UserEventLog {
+	return &instrumentedEventLog{UserEventLog: log, events: m.userEvents}
+}
+
+// instrumentedEventLog 统计发布事件数量的用户事件日志
+type instrumentedEventLog struct {
+	domain.UserEventLog
+	events *prometheus.CounterVec
+}
+
+// Publish 发布事件并计数
+func (l *instrumentedEventLog) Publish(ctx context.Context, event domain.UserEvent) {
+	l.UserEventLog.Publish(ctx, event)
+	l.events.WithLabelValues(eventTypeLabels[event.Type]).Inc()
+}
+
+// eventTypeLabels 事件类型对应的标签值
+var eventTypeLabels = map[domain.UserEventType]string{
+	domain.UserCreated: "created",
+	domain.UserUpdated: "updated",
+	domain.UserDeleted: "deleted",
+}
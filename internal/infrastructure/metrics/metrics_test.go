@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/eventbus"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, m.InstrumentGORM(db))
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, m.RegisterDBStats(sqlDB, "test"))
+
+	require.NoError(t, db.AutoMigrate(&widget{}))
+	require.NoError(t, db.Create(&widget{Name: "a"}).Error)
+	var w widget
+	assert.ErrorIs(t, db.First(&w, "name = ?", "b").Error, gorm.ErrRecordNotFound)
+
+	log := m.InstrumentUserEventLog(eventbus.NewUserEventLog(8))
+	log.Publish(context.Background(), domain.NewUserEvent(domain.UserCreated, &domain.User{ID: "1"}))
+	log.Publish(context.Background(), domain.NewUserEvent(domain.UserUpdated, &domain.User{ID: "1"}, "email"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.userEvents.WithLabelValues("created")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.userEvents.WithLabelValues("updated")))
+
+	m.ObserveGRPC("/user.v1.UserService/GetUser", "OK", time.Millisecond)
+	m.ObserveHTTP(http.MethodGet, "GET /api/users/{id}", http.StatusNotFound, time.Millisecond)
+	m.ObserveIDGenOverflow(500 * time.Microsecond)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.idgenWaits))
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	for _, want := range []string{
+		`grpc_server_handled_total{code="OK",method="/user.v1.UserService/GetUser"} 1`,
+		`http_server_requests_total{method="GET",route="GET /api/users/{id}",status="404"} 1`,
+		`db_query_duration_seconds_count{operation="create",status="ok",table="widgets"} 1`,
+		`db_query_duration_seconds_count{operation="query",status="ok",table="widgets"} 1`,
+		`go_sql_open_connections{db_name="test"}`,
+		`go_sql_wait_count_total{db_name="test"}`,
+		`idgen_sequence_overflow_waits_total 1`,
+		`go_goroutines`,
+	} {
+		assert.Contains(t, string(body), want)
+	}
+}
@@ -0,0 +1,61 @@
+package inmem
+
+import (
+	"context"
+	"sync"
+
+	"go-protos/internal/domain"
+)
+
+type InMemorySessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[string]domain.Session // key: id，保存副本避免调用方修改影响仓储
+}
+
+func NewInMemorySessionRepository() *InMemorySessionRepository {
+	return &InMemorySessionRepository{
+		sessions: make(map[string]domain.Session),
+	}
+}
+
+// 保存会话
+func (r *InMemorySessionRepository) Save(ctx context.Context, session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+// 条件保存轮换后的会话，存储中的刷新令牌已变化或会话已撤销时返回 ErrInvalidRefreshToken
+func (r *InMemorySessionRepository) SaveRotated(ctx context.Context, session *domain.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.sessions[session.ID]
+	if !ok || stored.RevokedAt != nil || stored.RefreshTokenHash != session.PreviousRefreshTokenHash {
+		return domain.ErrInvalidRefreshToken
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+// 根据ID查找会话
+func (r *InMemorySessionRepository) FindById(ctx context.Context, id string) (*domain.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+// deleteByUser 删除用户的全部会话，供彻底清除用户时调用
+func (r *InMemorySessionRepository) deleteByUser(userID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, id)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package inmem
+
+import (
+	"testing"
+
+	"go-protos/internal/infrastructure/persistence/persistencetest"
+)
+
+func TestInMemorySessionRepository_FindAndRotate(t *testing.T) {
+	persistencetest.SessionFindAndRotate(t, NewInMemorySessionRepository())
+}
@@ -2,6 +2,8 @@ package inmem
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -9,22 +11,37 @@ import (
 )
 
 type InMemoryUserRepository struct {
-	mu    sync.RWMutex
-	users map[string]*domain.User // key: id
+	mu       sync.RWMutex
+	users    map[string]*domain.User // key: id
+	roles    *InMemoryUserRoleRepository
+	sessions *InMemorySessionRepository
 }
 
+// NewInMemoryUserRepository 同时创建关联的角色和会话仓储，彻底清除用户时一并删除其数据
 func NewInMemoryUserRepository() *InMemoryUserRepository {
 	return &InMemoryUserRepository{
-		users: make(map[string]*domain.User),
+		users:    make(map[string]*domain.User),
+		roles:    NewInMemoryUserRoleRepository(),
+		sessions: NewInMemorySessionRepository(),
 	}
 }
 
+// Roles 与用户仓储关联的角色仓储
+func (r *InMemoryUserRepository) Roles() *InMemoryUserRoleRepository {
+	return r.roles
+}
+
+// Sessions 与用户仓储关联的会话仓储
+func (r *InMemoryUserRepository) Sessions() *InMemorySessionRepository {
+	return r.sessions
+}
+
 // 根据用户名查找
 func (r *InMemoryUserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	for _, user := range r.users {
-		if user.Username == username {
+		if user.Username == username && !user.IsDeleted() {
 			return user, nil
 		}
 	}
@@ -33,6 +50,17 @@ func (r *InMemoryUserRepository) FindByUsername(ctx context.Context, username st
 
 // 根据用户ID查找
 func (r *InMemoryUserRepository) FindById(ctx context.Context, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok || user.IsDeleted() {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// 根据用户ID查找（包含已软删除的用户）
+func (r *InMemoryUserRepository) FindByIdUnscoped(ctx context.Context, id string) (*domain.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	user, ok := r.users[id]
@@ -47,7 +75,7 @@ func (r *InMemoryUserRepository) FindByEmail(ctx context.Context, email string)
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	for _, user := range r.users {
-		if user.Email == email {
+		if user.Email == email && !user.IsDeleted() {
 			return user, nil
 		}
 	}
@@ -64,3 +92,128 @@ func (r *InMemoryUserRepository) Save(ctx context.Context, user *domain.User) er
 	r.users[user.ID] = user
 	return nil
 }
+
+// 批量查找用户名或邮箱命中的用户
+func (r *InMemoryUserRepository) FindByUsernamesOrEmails(ctx context.Context, usernames, emails []string, includeDeleted bool) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(usernames)+len(emails))
+	for _, username := range usernames {
+		wanted["u:"+username] = true
+	}
+	for _, email := range emails {
+		wanted["e:"+email] = true
+	}
+
+	var users []*domain.User
+	for _, user := range r.users {
+		if user.IsDeleted() && !includeDeleted {
+			continue
+		}
+		if wanted["u:"+user.Username] || (user.Email != "" && wanted["e:"+user.Email]) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+// 批量插入用户
+func (r *InMemoryUserRepository) SaveBatch(ctx context.Context, users []*domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range users {
+		if user.CreatedAt.IsZero() {
+			user.CreatedAt = time.Now()
+		}
+		r.users[user.ID] = user
+	}
+	return nil
+}
+
+// 物理删除用户
+func (r *InMemoryUserRepository) Purge(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return domain.ErrUserNotFound
+	}
+	delete(r.users, id)
+	r.roles.deleteByUser(id)
+	r.sessions.deleteByUser(id)
+	return nil
+}
+
+// 按条件分页查询用户
+func (r *InMemoryUserRepository) List(ctx context.Context, opts domain.UserListOptions) ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []*domain.User
+	for _, user := range r.users {
+		if !user.IsDeleted() && matchUserFilter(user, opts.Filter) && afterCursor(user, opts) {
+			users = append(users, user)
+		}
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		c := compareUsers(users[i], users[j], opts.OrderBy)
+		if opts.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	if opts.Limit > 0 && len(users) > opts.Limit {
+		users = users[:opts.Limit]
+	}
+	return users, nil
+}
+
+// matchUserFilter 判断用户是否满足过滤条件
+func matchUserFilter(user *domain.User, f domain.UserListFilter) bool {
+	if !f.CreatedAfter.IsZero() && user.CreatedAt.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !user.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	if f.EmailDomain != "" {
+		// 域名不区分大小写，与数据库默认排序规则下的 LIKE 一致
+		at := strings.LastIndex(user.Email, "@")
+		if at < 0 || !strings.EqualFold(user.Email[at+1:], f.EmailDomain) {
+			return false
+		}
+	}
+	if f.UsernamePrefix != "" && !strings.HasPrefix(user.Username, f.UsernamePrefix) {
+		return false
+	}
+	return true
+}
+
+// afterCursor 判断用户是否位于游标之后
+func afterCursor(user *domain.User, opts domain.UserListOptions) bool {
+	if opts.After == nil {
+		return true
+	}
+	cursor := &domain.User{ID: opts.After.ID, Username: opts.After.Username, CreatedAt: opts.After.CreatedAt}
+	c := compareUsers(user, cursor, opts.OrderBy)
+	if opts.Desc {
+		return c < 0
+	}
+	return c > 0
+}
+
+// compareUsers 按排序字段比较两个用户，ID 作为次级排序键
+func compareUsers(a, b *domain.User, orderBy domain.UserOrderField) int {
+	var c int
+	if orderBy == domain.OrderByUsername {
+		c = strings.Compare(a.Username, b.Username)
+	} else {
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
@@ -0,0 +1,53 @@
+package inmem
+
+import (
+	"context"
+	"testing"
+
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/persistence/persistencetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryUserRepository_ListEmailDomain(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	for _, u := range [][2]string{{"alice", "alice@Example.COM"}, {"bob", "bob@example.com.evil"}, {"carol", "carol@other.org"}} {
+		user, err := domain.NewUser(u[0], u[0], u[1], "hash")
+		require.NoError(t, err)
+		require.NoError(t, repo.Save(ctx, user))
+	}
+
+	// 域名不区分大小写，且必须与 @ 之后的部分完全一致
+	page, err := repo.List(ctx, domain.UserListOptions{Filter: domain.UserListFilter{EmailDomain: "example.com"}})
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "alice", page[0].Username)
+}
+
+func TestInMemoryUserRepository_SoftDeleteLifecycle(t *testing.T) {
+	persistencetest.UserSoftDeleteLifecycle(t, NewInMemoryUserRepository())
+}
+
+func TestInMemoryUserRepository_PurgeDeletesRolesAndSessions(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	user, err := domain.NewUser("alice", "alice", "alice@example.com", "hash")
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, user))
+	require.NoError(t, repo.Roles().AddRole(ctx, user.ID, domain.RoleAdmin))
+	require.NoError(t, repo.Sessions().Save(ctx, &domain.Session{ID: "s1", UserID: user.ID}))
+	require.NoError(t, repo.Sessions().Save(ctx, &domain.Session{ID: "s2", UserID: "other"}))
+
+	require.NoError(t, repo.Purge(ctx, user.ID))
+
+	roles, err := repo.Roles().ListRoles(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+	_, err = repo.Sessions().FindById(ctx, "s1")
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+	_, err = repo.Sessions().FindById(ctx, "s2")
+	assert.NoError(t, err)
+}
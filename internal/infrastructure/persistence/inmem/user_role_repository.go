@@ -0,0 +1,54 @@
+package inmem
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"go-protos/internal/domain"
+)
+
+type InMemoryUserRoleRepository struct {
+	mu    sync.RWMutex
+	roles map[string][]domain.Role // key: user id
+}
+
+func NewInMemoryUserRoleRepository() *InMemoryUserRoleRepository {
+	return &InMemoryUserRoleRepository{
+		roles: make(map[string][]domain.Role),
+	}
+}
+
+// 列出用户的角色
+func (r *InMemoryUserRoleRepository) ListRoles(ctx context.Context, userID string) ([]domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.roles[userID]), nil
+}
+
+// 授予角色
+func (r *InMemoryUserRoleRepository) AddRole(ctx context.Context, userID string, role domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.roles[userID], role) {
+		r.roles[userID] = append(r.roles[userID], role)
+	}
+	return nil
+}
+
+// 撤销角色
+func (r *InMemoryUserRoleRepository) RemoveRole(ctx context.Context, userID string, role domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.roles[userID] = slices.DeleteFunc(r.roles[userID], func(existing domain.Role) bool {
+		return existing == role
+	})
+	return nil
+}
+
+// deleteByUser 删除用户的全部角色，供彻底清除用户时调用
+func (r *InMemoryUserRoleRepository) deleteByUser(userID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.roles, userID)
+}
@@ -0,0 +1,53 @@
+package mariadb
+
+import (
+	"context"
+
+	"go-protos/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Save 保存会话
+func (r *SessionRepository) Save(ctx context.Context, session *domain.Session) error {
+	return r.db.WithContext(ctx).Save(session).Error
+}
+
+// SaveRotated 条件更新轮换后的会话，刷新令牌已被并发轮换或会话已撤销时返回 ErrInvalidRefreshToken
+func (r *SessionRepository) SaveRotated(ctx context.Context, session *domain.Session) error {
+	result := r.db.WithContext(ctx).Model(&domain.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, session.PreviousRefreshTokenHash).
+		Updates(map[string]any{
+			"refresh_token_hash":          session.RefreshTokenHash,
+			"previous_refresh_token_hash": session.PreviousRefreshTokenHash,
+			"expires_at":                  session.ExpiresAt,
+			"updated_at":                  session.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvalidRefreshToken
+	}
+	return nil
+}
+
+// FindById 根据ID查找会话
+func (r *SessionRepository) FindById(ctx context.Context, id string) (*domain.Session, error) {
+	var session domain.Session
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
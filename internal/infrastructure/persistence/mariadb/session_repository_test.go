@@ -0,0 +1,11 @@
+package mariadb
+
+import (
+	"testing"
+
+	"go-protos/internal/infrastructure/persistence/persistencetest"
+)
+
+func TestSessionRepository_FindAndRotate(t *testing.T) {
+	persistencetest.SessionFindAndRotate(t, NewSessionRepository(setupTestDB(t)))
+}
@@ -0,0 +1,27 @@
+package mariadb
+
+import (
+	"testing"
+
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/persistence/persistencetest"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupTestDB 仓储只使用 GORM 的通用查询，测试使用内存 SQLite 代替 MariaDB
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(&domain.User{}, &domain.UserRole{}, &domain.Session{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestUserRepository_SoftDeleteLifecycle(t *testing.T) {
+	persistencetest.UserSoftDeleteLifecycle(t, NewUserRepository(setupTestDB(t)))
+}
@@ -0,0 +1,42 @@
+package mariadb
+
+import (
+	"context"
+
+	"go-protos/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserRoleRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRoleRepository(db *gorm.DB) *UserRoleRepository {
+	return &UserRoleRepository{db: db}
+}
+
+// ListRoles 列出用户的角色
+func (r *UserRoleRepository) ListRoles(ctx context.Context, userID string) ([]domain.Role, error) {
+	var roles []domain.Role
+	err := r.db.WithContext(ctx).Model(&domain.UserRole{}).
+		Where("user_id = ?", userID).
+		Order("role").
+		Pluck("role", &roles).Error
+	return roles, err
+}
+
+// AddRole 授予角色，已拥有时忽略
+func (r *UserRoleRepository) AddRole(ctx context.Context, userID string, role domain.Role) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.UserRole{UserID: userID, Role: role}).Error
+}
+
+// RemoveRole 撤销角色
+func (r *UserRoleRepository) RemoveRole(ctx context.Context, userID string, role domain.Role) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND role = ?", userID, role).
+		Delete(&domain.UserRole{}).Error
+}
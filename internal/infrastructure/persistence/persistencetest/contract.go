@@ -0,0 +1,103 @@
+// Package persistencetest 仓储接口的契约测试，各存储实现在自己的测试中调用，保证行为一致
+package persistencetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-protos/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UserSoftDeleteLifecycle 验证软删除后的恢复和物理删除：
+// 已软删除的用户对普通查询不可见，FindByIdUnscoped 仍能找到，恢复或彻底清除都基于后者
+func UserSoftDeleteLifecycle(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+
+	// 软删除后恢复
+	restored := saveDeletedUser(t, repo, "lifecycle-1", "restore_me")
+	got, err := repo.FindByIdUnscoped(ctx, restored.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.True(t, got.IsDeleted())
+	got.Restore()
+	require.NoError(t, repo.Save(ctx, got))
+	got, err = repo.FindById(ctx, restored.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.False(t, got.IsDeleted())
+
+	// 软删除后彻底清除
+	purged := saveDeletedUser(t, repo, "lifecycle-2", "purge_me")
+	got, err = repo.FindByIdUnscoped(ctx, purged.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.NoError(t, repo.Purge(ctx, purged.ID))
+	assertUserNotFound(t, func() (*domain.User, error) { return repo.FindByIdUnscoped(ctx, purged.ID) })
+	assert.ErrorIs(t, repo.Purge(ctx, purged.ID), domain.ErrUserNotFound)
+}
+
+// SessionFindAndRotate 验证会话的查找和条件轮换：
+// 不存在的会话返回 ErrSessionNotFound，并发轮换同一版本时只有先保存的一方成功，已撤销的会话不能再轮换
+func SessionFindAndRotate(t *testing.T, repo domain.SessionRepository) {
+	ctx := context.Background()
+
+	_, err := repo.FindById(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+
+	session, _, err := domain.NewSession("s1", "u1", time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, session))
+
+	// 两个请求读到同一版本的会话并各自轮换，只有先保存的一方成功
+	first, err := repo.FindById(ctx, "s1")
+	require.NoError(t, err)
+	second, err := repo.FindById(ctx, "s1")
+	require.NoError(t, err)
+	_, err = first.Rotate(time.Hour)
+	require.NoError(t, err)
+	_, err = second.Rotate(time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.SaveRotated(ctx, first))
+	assert.ErrorIs(t, repo.SaveRotated(ctx, second), domain.ErrInvalidRefreshToken)
+
+	stored, err := repo.FindById(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, first.RefreshTokenHash, stored.RefreshTokenHash)
+	assert.Equal(t, session.RefreshTokenHash, stored.PreviousRefreshTokenHash)
+
+	// 已撤销的会话不能再轮换
+	stored.Revoke()
+	require.NoError(t, repo.Save(ctx, stored))
+	_, err = stored.Rotate(time.Hour)
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.SaveRotated(ctx, stored), domain.ErrInvalidRefreshToken)
+}
+
+// saveDeletedUser 创建用户并软删除，确认普通查询已不可见
+func saveDeletedUser(t *testing.T, repo domain.UserRepository, id, username string) *domain.User {
+	t.Helper()
+	ctx := context.Background()
+	user, err := domain.NewUser(id, username, username+"@example.com", "hash")
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, user))
+	user.MarkDeleted()
+	require.NoError(t, repo.Save(ctx, user))
+	assertUserNotFound(t, func() (*domain.User, error) { return repo.FindById(ctx, id) })
+	return user
+}
+
+// assertUserNotFound 未找到用户时部分实现返回 (nil, nil)，部分返回 ErrUserNotFound，两者都视为未找到
+func assertUserNotFound(t *testing.T, find func() (*domain.User, error)) {
+	t.Helper()
+	user, err := find()
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Nil(t, user)
+}
@@ -0,0 +1,60 @@
+package scopes
+
+import (
+	"strings"
+
+	"go-protos/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// likeEscaper 转义 LIKE 通配符，配合 ESCAPE '!' 使用（MySQL 与 SQLite 通用）
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// NotDeleted 排除已软删除的用户；领域模型的 DeletedAt 是普通时间字段，GORM 不会自动过滤
+func NotDeleted(db *gorm.DB) *gorm.DB {
+	return db.Where("deleted_at IS NULL")
+}
+
+// UserList 构造用户列表查询的过滤、键集分页、排序与数量限制，不包含已软删除的用户
+func UserList(opts domain.UserListOptions) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = NotDeleted(db)
+		f := opts.Filter
+		if !f.CreatedAfter.IsZero() {
+			db = db.Where("created_at >= ?", f.CreatedAfter)
+		}
+		if !f.CreatedBefore.IsZero() {
+			db = db.Where("created_at < ?", f.CreatedBefore)
+		}
+		if f.EmailDomain != "" {
+			db = db.Where("email LIKE ? ESCAPE '!'", "%@"+likeEscaper.Replace(f.EmailDomain))
+		}
+		if f.UsernamePrefix != "" {
+			db = db.Where("username LIKE ? ESCAPE '!'", likeEscaper.Replace(f.UsernamePrefix)+"%")
+		}
+
+		column := "created_at"
+		if opts.OrderBy == domain.OrderByUsername {
+			column = "username"
+		}
+		op, dir := ">", "ASC"
+		if opts.Desc {
+			op, dir = "<", "DESC"
+		}
+
+		if c := opts.After; c != nil {
+			var key any = c.CreatedAt
+			if opts.OrderBy == domain.OrderByUsername {
+				key = c.Username
+			}
+			db = db.Where("("+column+" "+op+" ? OR ("+column+" = ? AND id "+op+" ?))", key, key, c.ID)
+		}
+
+		db = db.Order(column + " " + dir).Order("id " + dir)
+		if opts.Limit > 0 {
+			db = db.Limit(opts.Limit)
+		}
+		return db
+	}
+}
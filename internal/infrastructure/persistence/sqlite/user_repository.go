@@ -4,10 +4,14 @@ import (
 	"context"
 
 	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/persistence/scopes"
 
 	"gorm.io/gorm"
 )
 
+// saveBatchSize 批量插入时每条 INSERT 语句包含的行数
+const saveBatchSize = 500
+
 type UserRepository struct {
 	db *gorm.DB
 }
@@ -19,11 +23,23 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 
 // 保存用户
 func (r *UserRepository) Save(ctx context.Context, u *domain.User) error {
-	return r.db.WithContext(ctx).Create(u).Error
+	return r.db.WithContext(ctx).Save(u).Error
 }
 
 // 根据ID查找
-func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+func (r *UserRepository) FindById(ctx context.Context, id string) (*domain.User, error) {
+	var u domain.User
+	if err := r.db.WithContext(ctx).Scopes(scopes.NotDeleted).First(&u, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// 根据ID查找（包含已软删除的用户）
+func (r *UserRepository) FindByIdUnscoped(ctx context.Context, id string) (*domain.User, error) {
 	var u domain.User
 	if err := r.db.WithContext(ctx).First(&u, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -37,7 +53,7 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User,
 // 根据用户名查找
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
 	var u domain.User
-	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&u).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(scopes.NotDeleted).Where("username = ?", username).First(&u).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrUserNotFound
 		}
@@ -49,7 +65,7 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 // 根据邮箱查找
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var u domain.User
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&u).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(scopes.NotDeleted).Where("email = ?", email).First(&u).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, domain.ErrUserNotFound
 		}
@@ -58,6 +74,67 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*domain
 	return &u, nil
 }
 
+// 按条件分页查询用户
+func (r *UserRepository) List(ctx context.Context, opts domain.UserListOptions) ([]*domain.User, error) {
+	var users []*domain.User
+	if err := r.db.WithContext(ctx).Scopes(scopes.UserList(opts)).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// 批量查找用户名或邮箱命中的用户
+func (r *UserRepository) FindByUsernamesOrEmails(ctx context.Context, usernames, emails []string, includeDeleted bool) ([]*domain.User, error) {
+	var users []*domain.User
+	if len(usernames) == 0 && len(emails) == 0 {
+		return users, nil
+	}
+
+	query := r.db.WithContext(ctx)
+	if !includeDeleted {
+		query = query.Scopes(scopes.NotDeleted)
+	}
+	switch {
+	case len(usernames) > 0 && len(emails) > 0:
+		query = query.Where("username IN ? OR email IN ?", usernames, emails)
+	case len(usernames) > 0:
+		query = query.Where("username IN ?", usernames)
+	default:
+		query = query.Where("email IN ?", emails)
+	}
+	if err := query.Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// 批量插入用户（单事务）
+func (r *UserRepository) SaveBatch(ctx context.Context, users []*domain.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(users, saveBatchSize).Error
+	})
+}
+
+// 物理删除用户，同一事务中删除其角色和会话
+func (r *UserRepository) Purge(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&domain.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrUserNotFound
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&domain.UserRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", id).Delete(&domain.Session{}).Error
+	})
+}
+
 // 更新状态
 func (r *UserRepository) UpdateStatus(ctx context.Context, id string, status int8) error {
 	return r.db.WithContext(ctx).
@@ -1,9 +1,16 @@
 package sqlite
 
 import (
-	"os/user"
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/persistence/persistencetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -14,7 +21,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("failed to connect sqlite: %v", err)
 	}
 	// 自动迁移
-	if err := db.AutoMigrate(&user.User{}); err != nil {
+	if err := db.AutoMigrate(&domain.User{}, &domain.UserRole{}, &domain.Session{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 	return db
@@ -48,3 +55,123 @@ func setupTestDB(t *testing.T) *gorm.DB {
 // 	assert.NoError(t, err)
 // 	assert.Equal(t, int8(3), updated.Status)
 // }
+
+func TestUserRepository_List(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(setupTestDB(t))
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, name := range []string{"carol", "alice", "bob", "al_ex"} {
+		u, err := domain.NewUser(fmt.Sprintf("id-%d", i), name, name+"@example.com", "hash")
+		require.NoError(t, err)
+		u.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		require.NoError(t, repo.Save(ctx, u))
+	}
+	other, err := domain.NewUser("id-9", "dave", "dave@other.org", "hash")
+	require.NoError(t, err)
+	other.CreatedAt = base.Add(9 * time.Hour)
+	require.NoError(t, repo.Save(ctx, other))
+
+	usernames := func(users []*domain.User) []string {
+		var names []string
+		for _, u := range users {
+			names = append(names, u.Username)
+		}
+		return names
+	}
+
+	// 按用户名升序，键集分页
+	page, err := repo.List(ctx, domain.UserListOptions{OrderBy: domain.OrderByUsername, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"al_ex", "alice"}, usernames(page))
+
+	page, err = repo.List(ctx, domain.UserListOptions{OrderBy: domain.OrderByUsername, Limit: 2, After: domain.CursorOf(page[1])})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "carol"}, usernames(page))
+
+	// 按创建时间降序
+	page, err = repo.List(ctx, domain.UserListOptions{OrderBy: domain.OrderByCreatedAt, Desc: true, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dave", "al_ex"}, usernames(page))
+
+	// 过滤：邮箱域名、用户名前缀（"_" 不作为通配符）、创建时间范围
+	page, err = repo.List(ctx, domain.UserListOptions{Filter: domain.UserListFilter{EmailDomain: "other.org"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dave"}, usernames(page))
+	page, err = repo.List(ctx, domain.UserListOptions{Filter: domain.UserListFilter{EmailDomain: "Other.ORG"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dave"}, usernames(page))
+
+	page, err = repo.List(ctx, domain.UserListOptions{OrderBy: domain.OrderByUsername, Filter: domain.UserListFilter{UsernamePrefix: "al_"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"al_ex"}, usernames(page))
+
+	page, err = repo.List(ctx, domain.UserListOptions{Filter: domain.UserListFilter{
+		CreatedAfter:  base.Add(time.Hour),
+		CreatedBefore: base.Add(3 * time.Hour),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, usernames(page))
+}
+
+func TestUserRepository_SoftDeleteAndPurge(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(setupTestDB(t))
+
+	u, err := domain.NewUser("u-1", "alice", "alice@example.com", "hash")
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, u))
+
+	// 软删除后默认查询不可见
+	u.MarkDeleted()
+	require.NoError(t, repo.Save(ctx, u))
+	_, err = repo.FindById(ctx, "u-1")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	_, err = repo.FindByUsername(ctx, "alice")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	got, err := repo.FindByIdUnscoped(ctx, "u-1")
+	require.NoError(t, err)
+	assert.True(t, got.IsDeleted())
+
+	found, err := repo.FindByUsernamesOrEmails(ctx, []string{"alice"}, nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+	found, err = repo.FindByUsernamesOrEmails(ctx, []string{"alice"}, nil, true)
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	// 已删除用户不占用唯一约束
+	reuse, err := domain.NewUser("u-2", "alice", "alice@example.com", "hash")
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, reuse))
+
+	// 物理删除，同时删除角色和会话
+	roles, sessions := NewUserRoleRepository(repo.db), NewSessionRepository(repo.db)
+	for _, id := range []string{"u-1", "u-2"} {
+		require.NoError(t, roles.AddRole(ctx, id, domain.RoleAdmin))
+		session, _, err := domain.NewSession("s-"+id, id, time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, sessions.Save(ctx, session))
+	}
+	require.NoError(t, repo.Purge(ctx, "u-1"))
+	_, err = repo.FindByIdUnscoped(ctx, "u-1")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	assert.ErrorIs(t, repo.Purge(ctx, "u-1"), domain.ErrUserNotFound)
+
+	purgedRoles, err := roles.ListRoles(ctx, "u-1")
+	require.NoError(t, err)
+	assert.Empty(t, purgedRoles)
+	_, err = sessions.FindById(ctx, "s-u-1")
+	assert.ErrorIs(t, err, domain.ErrSessionNotFound)
+
+	keptRoles, err := roles.ListRoles(ctx, "u-2")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Role{domain.RoleAdmin}, keptRoles)
+	_, err = sessions.FindById(ctx, "s-u-2")
+	assert.NoError(t, err)
+}
+
+func TestUserRepository_SoftDeleteLifecycle(t *testing.T) {
+	persistencetest.UserSoftDeleteLifecycle(t, NewUserRepository(setupTestDB(t)))
+}
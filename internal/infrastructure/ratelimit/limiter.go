@@ -0,0 +1,171 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go-protos/internal/domain"
+)
+
+// KeyBy 限流键的来源
+type KeyBy string
+
+const (
+	// KeyByPrincipal 按认证后的调用方（用户ID或服务账号名）限流，匿名调用退化为按IP
+	KeyByPrincipal KeyBy = "principal"
+	// KeyByIP 按对端IP限流
+	KeyByIP KeyBy = "ip"
+	// KeyByGlobal 所有调用方共享一个令牌桶
+	KeyByGlobal KeyBy = "global"
+)
+
+// Rule 限流规则
+// Match 为 gRPC 全方法名或 HTTP 路径，HTTP 路径可以带方法前缀（如 "POST /api/users"）；
+// 以 "/" 结尾时按前缀匹配，否则精确匹配，多条规则命中时取最长的一条
+type Rule struct {
+	Match string
+	KeyBy KeyBy
+	Limit Limit
+}
+
+// Caller 发起调用的一方
+type Caller struct {
+	Principal *domain.Principal // 未认证时为空
+	IP        string
+}
+
+// Decision 限流判定结果
+type Decision struct {
+	Result
+	Rule *Rule // 命中的规则，未命中任何规则且没有默认规则时为空
+}
+
+// Limiter 按方法或路由、按调用方限流的令牌桶限流器，gRPC 和 HTTP 入口共用
+// 规则和开关可在运行时通过 Update、SetEnabled 调整
+type Limiter struct {
+	store   Store
+	rules   atomic.Pointer[ruleSet]
+	enabled atomic.Bool
+	now     func() time.Time
+}
+
+// ruleSet 一组生效的规则，替换时整体替换
+type ruleSet struct {
+	rules       []Rule
+	defaultRule *Rule
+}
+
+// NewLimiter 创建启用状态的限流器，defaultRule 为空时未命中规则的调用不限流
+func NewLimiter(store Store, rules []Rule, defaultRule *Rule) (*Limiter, error) {
+	l := &Limiter{
+		store: store,
+		now:   time.Now,
+	}
+	if err := l.Update(rules, defaultRule); err != nil {
+		return nil, err
+	}
+	l.enabled.Store(true)
+	return l, nil
+}
+
+// Update 校验并替换规则，校验失败时保留原有规则；令牌桶状态按规则的 Match 保留
+func (l *Limiter) Update(rules []Rule, defaultRule *Rule) error {
+	all := rules
+	if defaultRule != nil {
+		all = append(all[:len(all):len(all)], *defaultRule)
+	}
+	for _, rule := range all {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	l.rules.Store(&ruleSet{rules: rules, defaultRule: defaultRule})
+	return nil
+}
+
+// SetEnabled 启用或停用限流，停用时所有调用放行
+func (l *Limiter) SetEnabled(enabled bool) {
+	l.enabled.Store(enabled)
+}
+
+// validate 校验规则参数
+func (r Rule) validate() error {
+	if r.Limit.Rate <= 0 {
+		return fmt.Errorf("rate limit rule %q: rate must be positive", r.Match)
+	}
+	if r.Limit.Burst < 1 {
+		return fmt.Errorf("rate limit rule %q: burst must be at least 1", r.Match)
+	}
+	switch r.KeyBy {
+	case KeyByPrincipal, KeyByIP, KeyByGlobal:
+	default:
+		return fmt.Errorf("rate limit rule %q: unsupported key_by %q", r.Match, r.KeyBy)
+	}
+	return nil
+}
+
+// Allow 判定一次调用是否放行。target 为 gRPC 全方法名，HTTP 调用时 method 为请求方法
+// 存储出错时放行并记录日志，避免共享存储故障导致服务整体不可用
+func (l *Limiter) Allow(ctx context.Context, method, target string, caller Caller) Decision {
+	if !l.enabled.Load() {
+		return Decision{Result: Result{Allowed: true}}
+	}
+	rule := l.rules.Load().match(method, target)
+	if rule == nil {
+		return Decision{Result: Result{Allowed: true}}
+	}
+
+	key := rule.Match + "|" + callerKey(rule.KeyBy, caller)
+	result, err := l.store.Take(ctx, key, rule.Limit, l.now())
+	if err != nil {
+		slog.WarnContext(ctx, "rate limit store unavailable, allowing request",
+			slog.String("rule", rule.Match),
+			slog.Any("error", err),
+		)
+		return Decision{Result: Result{Allowed: true}, Rule: rule}
+	}
+	return Decision{Result: result, Rule: rule}
+}
+
+// match 查找命中的规则，都未命中时返回默认规则
+func (s *ruleSet) match(method, target string) *Rule {
+	var (
+		matched *Rule
+		longest = -1
+	)
+	for i := range s.rules {
+		rule := &s.rules[i]
+		pattern := rule.Match
+		if m, path, ok := strings.Cut(pattern, " "); ok {
+			if m != method {
+				continue
+			}
+			pattern = path
+		}
+		hit := pattern == target || (strings.HasSuffix(pattern, "/") && strings.HasPrefix(target, pattern))
+		if hit && len(rule.Match) > longest {
+			matched, longest = rule, len(rule.Match)
+		}
+	}
+	if matched == nil {
+		return s.defaultRule
+	}
+	return matched
+}
+
+// callerKey 按规则计算调用方的限流键
+func callerKey(keyBy KeyBy, caller Caller) string {
+	switch keyBy {
+	case KeyByGlobal:
+		return "global"
+	case KeyByPrincipal:
+		if caller.Principal != nil {
+			return string(caller.Principal.Type) + ":" + caller.Principal.ID
+		}
+	}
+	return "ip:" + caller.IP
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-protos/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStore 总是返回错误的存储
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit, time.Time) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	ctx := context.Background()
+	limiter, err := NewLimiter(NewMemoryStore(), []Rule{
+		{Match: "/user.v1.UserService/CreateUser", KeyBy: KeyByPrincipal, Limit: Limit{Rate: 1, Burst: 2}},
+	}, nil)
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	limiter.now = func() time.Time { return now }
+
+	alice := Caller{Principal: &domain.Principal{Type: domain.PrincipalUser, ID: "u-alice"}, IP: "10.0.0.1"}
+	bob := Caller{Principal: &domain.Principal{Type: domain.PrincipalUser, ID: "u-bob"}, IP: "10.0.0.1"}
+
+	// 桶容量用完后拒绝，并给出等待时间
+	assert.True(t, limiter.Allow(ctx, "", "/user.v1.UserService/CreateUser", alice).Allowed)
+	assert.True(t, limiter.Allow(ctx, "", "/user.v1.UserService/CreateUser", alice).Allowed)
+	denied := limiter.Allow(ctx, "", "/user.v1.UserService/CreateUser", alice)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+
+	// 不同调用方使用各自的令牌桶，未命中规则的方法不限流
+	assert.True(t, limiter.Allow(ctx, "", "/user.v1.UserService/CreateUser", bob).Allowed)
+	assert.True(t, limiter.Allow(ctx, "", "/user.v1.UserService/GetUserById", alice).Allowed)
+
+	// 按速率补充令牌
+	now = now.Add(500 * time.Millisecond)
+	denied = limiter.Allow(ctx, "", "/user.v1.UserService/CreateUser", alice)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, 500*time.Millisecond, denied.RetryAfter)
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, limiter.Allow(ctx, "", "/user.v1.UserService/CreateUser", alice).Allowed)
+}
+
+func TestLimiter_Match(t *testing.T) {
+	ctx := context.Background()
+	limiter, err := NewLimiter(NewMemoryStore(), []Rule{
+		{Match: "POST /api/users", KeyBy: KeyByIP, Limit: Limit{Rate: 1, Burst: 1}},
+		{Match: "/api/", KeyBy: KeyByGlobal, Limit: Limit{Rate: 100, Burst: 100}},
+	}, &Rule{Match: "*", KeyBy: KeyByGlobal, Limit: Limit{Rate: 1000, Burst: 1000}})
+	require.NoError(t, err)
+
+	caller := Caller{IP: "10.0.0.1"}
+	assert.Equal(t, "POST /api/users", limiter.Allow(ctx, "POST", "/api/users", caller).Rule.Match)
+	assert.Equal(t, "/api/", limiter.Allow(ctx, "GET", "/api/users", caller).Rule.Match)
+	assert.Equal(t, "*", limiter.Allow(ctx, "", "/user.v1.UserService/GetUserById", caller).Rule.Match)
+
+	_, err = NewLimiter(NewMemoryStore(), []Rule{{Match: "/api/", KeyBy: "user", Limit: Limit{Rate: 1, Burst: 1}}}, nil)
+	assert.Error(t, err)
+	_, err = NewLimiter(NewMemoryStore(), nil, &Rule{Match: "*", KeyBy: KeyByIP, Limit: Limit{Rate: 0, Burst: 1}})
+	assert.Error(t, err)
+}
+
+func TestLimiter_StoreFailureAllows(t *testing.T) {
+	limiter, err := NewLimiter(failingStore{}, []Rule{{Match: "/api/", KeyBy: KeyByIP, Limit: Limit{Rate: 1, Burst: 1}}}, nil)
+	require.NoError(t, err)
+	assert.True(t, limiter.Allow(context.Background(), "GET", "/api/users", Caller{IP: "10.0.0.1"}).Allowed)
+}
+
+func TestLimiter_UpdateAndDisable(t *testing.T) {
+	ctx := context.Background()
+	limiter, err := NewLimiter(NewMemoryStore(), []Rule{{Match: "/api/", KeyBy: KeyByGlobal, Limit: Limit{Rate: 1, Burst: 1}}}, nil)
+	require.NoError(t, err)
+	caller := Caller{IP: "10.0.0.1"}
+
+	assert.True(t, limiter.Allow(ctx, "GET", "/api/users", caller).Allowed)
+	assert.False(t, limiter.Allow(ctx, "GET", "/api/users", caller).Allowed)
+
+	// 停用后全部放行
+	limiter.SetEnabled(false)
+	assert.True(t, limiter.Allow(ctx, "GET", "/api/users", caller).Allowed)
+	limiter.SetEnabled(true)
+
+	// 不合法的规则被拒绝，原有规则继续生效
+	assert.Error(t, limiter.Update([]Rule{{Match: "/api/", KeyBy: KeyByGlobal}}, nil))
+	assert.False(t, limiter.Allow(ctx, "GET", "/api/users", caller).Allowed)
+
+	// 替换规则后按新规则判定
+	require.NoError(t, limiter.Update([]Rule{{Match: "/other/", KeyBy: KeyByGlobal, Limit: Limit{Rate: 1, Burst: 1}}}, nil))
+	decision := limiter.Allow(ctx, "GET", "/api/users", caller)
+	assert.True(t, decision.Allowed)
+	assert.Nil(t, decision.Rule)
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/security/token"
+)
+
+// APIKey 服务账号API密钥，配置中只保存密钥的 SHA-256，不保存明文
+type APIKey struct {
+	Name   string   // 服务账号名
+	SHA256 string   // 密钥的 SHA-256（十六进制）
+	Scopes []string // 授予的权限范围
+}
+
+// TokenVerifier 访问令牌校验端口
+type TokenVerifier interface {
+	Verify(tokenString string) (*token.Claims, error)
+}
+
+// Authenticator 校验调用方凭证（Bearer 访问令牌或API密钥）并解析出调用方
+type Authenticator struct {
+	verifier TokenVerifier
+	apiKeys  map[string]APIKey // key: 密钥的 SHA-256
+}
+
+// NewAuthenticator 创建认证器
+func NewAuthenticator(verifier TokenVerifier, apiKeys []APIKey) (*Authenticator, error) {
+	a := &Authenticator{
+		verifier: verifier,
+		apiKeys:  make(map[string]APIKey, len(apiKeys)),
+	}
+	for _, key := range apiKeys {
+		digest := strings.ToLower(key.SHA256)
+		if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("api key %q: sha256 must be 64 hex characters", key.Name)
+		}
+		if key.Name == "" {
+			return nil, fmt.Errorf("api key name is required")
+		}
+		key.SHA256 = digest
+		a.apiKeys[digest] = key
+	}
+	return a, nil
+}
+
+// AuthenticateBearer 校验 Bearer 访问令牌
+func (a *Authenticator) AuthenticateBearer(accessToken string) (*domain.Principal, error) {
+	claims, err := a.verifier.Verify(accessToken)
+	if err != nil {
+		return nil, domain.ErrInvalidAccessToken
+	}
+	return &domain.Principal{
+		Type:      domain.PrincipalUser,
+		ID:        claims.Subject,
+		Name:      claims.Username,
+		SessionID: claims.SessionID,
+	}, nil
+}
+
+// AuthenticateAPIKey 校验服务账号API密钥
+// 按密钥哈希查找，比较的是哈希而非明文，不会泄露密钥的时序信息
+func (a *Authenticator) AuthenticateAPIKey(apiKey string) (*domain.Principal, error) {
+	key, ok := a.apiKeys[HashAPIKey(apiKey)]
+	if !ok {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	return &domain.Principal{
+		Type:   domain.PrincipalService,
+		ID:     key.Name,
+		Name:   key.Name,
+		Scopes: key.Scopes,
+	}, nil
+}
+
+// HashAPIKey 计算API密钥的 SHA-256，用于生成配置
+func HashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,43 @@
+package auth
+
+import "strings"
+
+// Policy 按方法名（gRPC 全方法名或 HTTP 路径）区分公开和受保护的访问策略
+// 规则以 "/" 结尾时按前缀匹配（如 "/grpc.health.v1.Health/" 匹配整个服务），否则精确匹配；
+// 多条规则命中时取最长的一条，长度相同时受保护优先；都未命中时使用默认策略
+type Policy struct {
+	defaultPublic bool
+	public        []string
+	protected     []string
+}
+
+// NewPolicy 创建访问策略
+func NewPolicy(defaultPublic bool, public, protected []string) *Policy {
+	return &Policy{
+		defaultPublic: defaultPublic,
+		public:        public,
+		protected:     protected,
+	}
+}
+
+// IsPublic 判断方法是否无需认证
+func (p *Policy) IsPublic(method string) bool {
+	publicMatch := longestMatch(p.public, method)
+	protectedMatch := longestMatch(p.protected, method)
+	if publicMatch < 0 && protectedMatch < 0 {
+		return p.defaultPublic
+	}
+	return publicMatch > protectedMatch
+}
+
+// longestMatch 返回命中规则的最大长度，未命中返回 -1
+func longestMatch(rules []string, method string) int {
+	longest := -1
+	for _, rule := range rules {
+		matched := rule == method || (strings.HasSuffix(rule, "/") && strings.HasPrefix(method, rule))
+		if matched && len(rule) > longest {
+			longest = len(rule)
+		}
+	}
+	return longest
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_IsPublic(t *testing.T) {
+	policy := NewPolicy(false,
+		[]string{"/grpc.health.v1.Health/", "/user.v1.UserService/", "/auth.v1.AuthService/Authenticate"},
+		[]string{"/user.v1.UserService/PurgeUser", "/auth.v1.AuthService/Authenticate"},
+	)
+
+	assert.True(t, policy.IsPublic("/grpc.health.v1.Health/Check"))
+	assert.True(t, policy.IsPublic("/user.v1.UserService/GetUserById"))
+	// 更具体的规则优先
+	assert.False(t, policy.IsPublic("/user.v1.UserService/PurgeUser"))
+	// 长度相同时受保护优先
+	assert.False(t, policy.IsPublic("/auth.v1.AuthService/Authenticate"))
+	// 未命中时使用默认策略
+	assert.False(t, policy.IsPublic("/user.v2.UserService/GetUser"))
+	assert.True(t, NewPolicy(true, nil, nil).IsPublic("/user.v2.UserService/GetUser"))
+}
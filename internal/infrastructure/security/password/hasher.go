@@ -0,0 +1,85 @@
+package password
+
+import (
+	"fmt"
+
+	"go-protos/internal/domain"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 支持的算法名称
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+// Config 密码哈希配置
+type Config struct {
+	Algorithm  string // 新哈希使用的算法
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+// algorithmHasher 可识别自身哈希格式的具体算法实现
+type algorithmHasher interface {
+	domain.PasswordHasher
+	Supports(hash string) bool
+}
+
+// Hasher 组合哈希器：新密码使用配置的主算法，校验时按哈希格式选择算法，
+// 因此切换算法后旧哈希仍可校验，并在下次校验成功时被升级
+type Hasher struct {
+	primary    algorithmHasher
+	algorithms []algorithmHasher
+}
+
+// New 根据配置创建密码哈希器
+func New(cfg Config) (*Hasher, error) {
+	if cfg.BcryptCost == 0 {
+		cfg.BcryptCost = bcrypt.DefaultCost
+	}
+	if cfg.Argon2 == (Argon2Params{}) {
+		cfg.Argon2 = DefaultArgon2Params
+	}
+
+	bcryptHasher, err := NewBcryptHasher(cfg.BcryptCost)
+	if err != nil {
+		return nil, err
+	}
+	argon2Hasher, err := NewArgon2idHasher(cfg.Argon2)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Hasher{algorithms: []algorithmHasher{bcryptHasher, argon2Hasher}}
+	switch cfg.Algorithm {
+	case AlgorithmBcrypt:
+		h.primary = bcryptHasher
+	case AlgorithmArgon2id:
+		h.primary = argon2Hasher
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %q", cfg.Algorithm)
+	}
+	return h, nil
+}
+
+// Hash 使用主算法生成哈希
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.primary.Hash(password)
+}
+
+// Verify 按哈希格式选择算法校验；无法识别的格式视为不匹配
+func (h *Hasher) Verify(hash, password string) (bool, error) {
+	for _, a := range h.algorithms {
+		if a.Supports(hash) {
+			return a.Verify(hash, password)
+		}
+	}
+	return false, nil
+}
+
+// NeedsRehash 哈希不是主算法生成或参数已变化时需要重新生成
+func (h *Hasher) NeedsRehash(hash string) bool {
+	return !h.primary.Supports(hash) || h.primary.NeedsRehash(hash)
+}
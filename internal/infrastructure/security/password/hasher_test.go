@@ -0,0 +1,67 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testArgon2Params = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHasherRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmBcrypt, AlgorithmArgon2id} {
+		t.Run(algorithm, func(t *testing.T) {
+			h, err := New(Config{Algorithm: algorithm, BcryptCost: 4, Argon2: testArgon2Params})
+			require.NoError(t, err)
+
+			hash, err := h.Hash("s3cret-password")
+			require.NoError(t, err)
+			assert.NotEqual(t, "s3cret-password", hash)
+
+			ok, err := h.Verify(hash, "s3cret-password")
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = h.Verify(hash, "wrong-password")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.False(t, h.NeedsRehash(hash))
+		})
+	}
+}
+
+func TestHasherNeedsRehash(t *testing.T) {
+	bcryptHasher, err := New(Config{Algorithm: AlgorithmBcrypt, BcryptCost: 4, Argon2: testArgon2Params})
+	require.NoError(t, err)
+	bcryptHash, err := bcryptHasher.Hash("s3cret-password")
+	require.NoError(t, err)
+
+	// cost 变化
+	stronger, err := New(Config{Algorithm: AlgorithmBcrypt, BcryptCost: 5, Argon2: testArgon2Params})
+	require.NoError(t, err)
+	assert.True(t, stronger.NeedsRehash(bcryptHash))
+
+	// 算法切换：旧的 bcrypt 哈希仍可校验，但需要升级
+	argon2Hasher, err := New(Config{Algorithm: AlgorithmArgon2id, BcryptCost: 4, Argon2: testArgon2Params})
+	require.NoError(t, err)
+	ok, err := argon2Hasher.Verify(bcryptHash, "s3cret-password")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, argon2Hasher.NeedsRehash(bcryptHash))
+
+	// argon2 参数变化
+	argon2Hash, err := argon2Hasher.Hash("s3cret-password")
+	require.NoError(t, err)
+	params := testArgon2Params
+	params.Iterations = 2
+	tuned, err := New(Config{Algorithm: AlgorithmArgon2id, BcryptCost: 4, Argon2: params})
+	require.NoError(t, err)
+	assert.True(t, tuned.NeedsRehash(argon2Hash))
+}
+
+func TestNewRejectsUnknownAlgorithm(t *testing.T) {
+	_, err := New(Config{Algorithm: "md5", BcryptCost: 4, Argon2: testArgon2Params})
+	assert.Error(t, err)
+}
@@ -0,0 +1,51 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+)
+
+// ClientIdentity 通过校验的客户端证书身份
+type ClientIdentity struct {
+	Subject      string   // 证书主题 DN
+	CommonName   string   // 主题 CN
+	DNSNames     []string // SAN 中的 DNS 名称
+	URIs         []string // SAN 中的 URI，如 SPIFFE ID
+	SerialNumber string
+	Fingerprint  string // 证书 DER 的 SHA-256（十六进制）
+}
+
+type clientIdentityKey struct{}
+
+// IdentityFromConnectionState 从握手结果中提取客户端身份，没有通过校验的客户端证书时返回 nil
+func IdentityFromConnectionState(state *tls.ConnectionState) *ClientIdentity {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	leaf := state.VerifiedChains[0][0]
+	fingerprint := sha256.Sum256(leaf.Raw)
+	identity := &ClientIdentity{
+		Subject:      leaf.Subject.String(),
+		CommonName:   leaf.Subject.CommonName,
+		DNSNames:     leaf.DNSNames,
+		SerialNumber: leaf.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(fingerprint[:]),
+	}
+	for _, uri := range leaf.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity
+}
+
+// ContextWithClientIdentity 将客户端证书身份写入上下文
+func ContextWithClientIdentity(ctx context.Context, identity *ClientIdentity) context.Context {
+	return context.WithValue(ctx, clientIdentityKey{}, identity)
+}
+
+// ClientIdentityFromContext 从上下文读取客户端证书身份
+func ClientIdentityFromContext(ctx context.Context) (*ClientIdentity, bool) {
+	identity, ok := ctx.Value(clientIdentityKey{}).(*ClientIdentity)
+	return identity, ok && identity != nil
+}
@@ -0,0 +1,207 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientAuth 客户端证书校验方式
+type ClientAuth string
+
+const (
+	// ClientAuthNone 不要求客户端证书
+	ClientAuthNone ClientAuth = "none"
+	// ClientAuthRequest 客户端提供证书时校验，未提供时放行
+	ClientAuthRequest ClientAuth = "request"
+	// ClientAuthRequire 必须提供并通过校验的客户端证书（mTLS）
+	ClientAuthRequire ClientAuth = "require"
+)
+
+// DefaultReloadInterval 默认的证书文件检查间隔
+const DefaultReloadInterval = time.Minute
+
+// Config TLS配置
+type Config struct {
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string     // 校验客户端证书的CA，ClientAuth 不为 none 时必填
+	ClientAuth     ClientAuth // 为空时：配置了 ClientCAFile 则为 require，否则为 none
+	MinVersion     string     // 1.2 / 1.3，默认 1.2
+	ReloadInterval time.Duration
+}
+
+// Reloader 持有当前的证书和客户端CA，定期检查文件变化并在轮换后重新加载，无需重启服务
+type Reloader struct {
+	cfg        Config
+	minVersion uint16
+	clientAuth tls.ClientAuthType
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// NewReloader 加载证书并创建重载器，文件不可用或配置不合法时返回错误
+func NewReloader(cfg Config) (*Reloader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("tls cert file and key file are required")
+	}
+	if cfg.ClientAuth == "" {
+		cfg.ClientAuth = ClientAuthNone
+		if cfg.ClientCAFile != "" {
+			cfg.ClientAuth = ClientAuthRequire
+		}
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = DefaultReloadInterval
+	}
+
+	r := &Reloader{cfg: cfg}
+	switch cfg.MinVersion {
+	case "", "1.2":
+		r.minVersion = tls.VersionTLS12
+	case "1.3":
+		r.minVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("unsupported tls min version %q", cfg.MinVersion)
+	}
+	switch cfg.ClientAuth {
+	case ClientAuthNone:
+		r.clientAuth = tls.NoClientCert
+	case ClientAuthRequest:
+		r.clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		r.clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unsupported tls client auth %q", cfg.ClientAuth)
+	}
+	if cfg.ClientAuth != ClientAuthNone && cfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("tls client auth %q requires a client CA file", cfg.ClientAuth)
+	}
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ServerConfig 返回服务端 TLS 配置，每次握手都使用最新加载的证书和客户端CA
+// nextProtos 为 ALPN 协议（HTTP 服务传入 "h2"、"http/1.1"，gRPC 由 credentials 自动追加）
+func (r *Reloader) ServerConfig(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: r.minVersion,
+		NextProtos: nextProtos,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return r.cert, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   r.minVersion,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*r.cert},
+				ClientAuth:   r.clientAuth,
+				ClientCAs:    r.clientCAs,
+			}, nil
+		},
+	}
+}
+
+// Run 定期检查证书文件，变化时重新加载，直到 ctx 结束
+// 加载失败时保留当前证书并记录日志，避免轮换过程中的中间状态导致服务不可用
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.changed()
+			if err == nil && changed {
+				err = r.load()
+				if err == nil {
+					slog.Info("tls certificate reloaded", slog.String("cert_file", r.cfg.CertFile))
+				}
+			}
+			if err != nil {
+				slog.Warn("tls certificate reload failed, keeping current certificate",
+					slog.String("cert_file", r.cfg.CertFile),
+					slog.Any("error", err),
+				)
+			}
+		}
+	}
+}
+
+// files 需要监视的文件
+func (r *Reloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// changed 比较文件修改时间判断是否需要重新加载
+func (r *Reloader) changed() (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		if !info.ModTime().Equal(r.modTimes[file]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// load 读取证书、私钥和客户端CA，全部成功后才替换当前配置
+func (r *Reloader) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load tls key pair: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pemData, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read tls client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pemData) {
+			return fmt.Errorf("tls client CA file %s contains no certificates", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	return nil
+}
@@ -0,0 +1,187 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA 测试用的自签名CA
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue 签发证书，返回 PEM 编码的证书和私钥
+func (ca *testCA) issue(t *testing.T, commonName string, serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestReloader_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "server.local", 2, x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "billing-service", 3, x509.ExtKeyUsageClientAuth)
+	now := time.Now()
+	writeFile(t, filepath.Join(dir, "server.crt"), serverCert, now)
+	writeFile(t, filepath.Join(dir, "server.key"), serverKey, now)
+	writeFile(t, filepath.Join(dir, "ca.crt"), ca.pem, now)
+
+	reloader, err := NewReloader(Config{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		MinVersion:   "1.3",
+	})
+	require.NoError(t, err)
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", reloader.ServerConfig())
+	require.NoError(t, err)
+	defer lis.Close()
+	identities := make(chan *ClientIdentity, 2)
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			tlsConn := conn.(*tls.Conn)
+			if tlsConn.Handshake() == nil {
+				state := tlsConn.ConnectionState()
+				identities <- IdentityFromConnectionState(&state)
+			}
+			conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	keyPair, err := tls.X509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{RootCAs: roots, ServerName: "server.local", Certificates: []tls.Certificate{keyPair}})
+	require.NoError(t, err)
+	conn.Close()
+	identity := <-identities
+	require.NotNil(t, identity)
+	assert.Equal(t, "billing-service", identity.CommonName)
+	assert.Equal(t, []string{"billing-service"}, identity.DNSNames)
+	assert.Equal(t, "3", identity.SerialNumber)
+	assert.Len(t, identity.Fingerprint, 64)
+
+	// 未提供客户端证书时握手失败
+	conn, err = tls.Dial("tcp", lis.Addr().String(), &tls.Config{RootCAs: roots, ServerName: "server.local"})
+	if err == nil {
+		// TLS 1.3 中客户端证书错误在首次读取时才会暴露
+		_, err = conn.Read(make([]byte, 1))
+		conn.Close()
+	}
+	assert.Error(t, err)
+}
+
+func TestReloader_ReloadOnChange(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	cert, key := ca.issue(t, "server.local", 10, x509.ExtKeyUsageServerAuth)
+	now := time.Now()
+	writeFile(t, certFile, cert, now)
+	writeFile(t, keyFile, key, now)
+
+	reloader, err := NewReloader(Config{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err)
+	current := func() *x509.Certificate {
+		cfg, err := reloader.ServerConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+		require.NoError(t, err)
+		return leaf
+	}
+	assert.Equal(t, int64(10), current().SerialNumber.Int64())
+
+	changed, err := reloader.changed()
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// 轮换证书后重新加载
+	cert, key = ca.issue(t, "server.local", 11, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert, now.Add(time.Minute))
+	writeFile(t, keyFile, key, now.Add(time.Minute))
+	changed, err = reloader.changed()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	require.NoError(t, reloader.load())
+	assert.Equal(t, int64(11), current().SerialNumber.Int64())
+
+	// 写入不完整的文件时加载失败，保留当前证书
+	writeFile(t, keyFile, []byte("not a key"), now.Add(2*time.Minute))
+	assert.Error(t, reloader.load())
+	assert.Equal(t, int64(11), current().SerialNumber.Int64())
+}
+
+func TestNewReloader_InvalidConfig(t *testing.T) {
+	_, err := NewReloader(Config{})
+	assert.Error(t, err)
+	_, err = NewReloader(Config{CertFile: "a", KeyFile: "b", ClientAuth: ClientAuthRequire})
+	assert.ErrorContains(t, err, "client CA")
+	_, err = NewReloader(Config{CertFile: "a", KeyFile: "b", MinVersion: "1.0"})
+	assert.ErrorContains(t, err, "min version")
+}
@@ -0,0 +1,89 @@
+package token
+
+import (
+	"fmt"
+	"time"
+
+	"go-protos/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// DefaultAccessTTL 默认访问令牌有效期
+const DefaultAccessTTL = 15 * time.Minute
+
+// Config 访问令牌配置
+type Config struct {
+	Issuer       string
+	AccessTTL    time.Duration
+	SigningKeyID string // 签发使用的密钥，其余密钥只用于校验
+	Keys         []KeyConfig
+}
+
+// Claims 访问令牌声明
+type Claims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid,omitempty"`
+	Username  string `json:"preferred_username,omitempty"`
+}
+
+// Issuer JWT 访问令牌签发器
+type Issuer struct {
+	issuer    string
+	accessTTL time.Duration
+	key       *Key
+	keys      *KeySet
+}
+
+// NewIssuer 根据配置创建签发器
+func NewIssuer(cfg Config) (*Issuer, error) {
+	if cfg.AccessTTL <= 0 {
+		cfg.AccessTTL = DefaultAccessTTL
+	}
+
+	keys, err := NewKeySet(cfg.Keys)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := keys.Get(cfg.SigningKeyID)
+	if !ok {
+		return nil, fmt.Errorf("signing key %q is not configured", cfg.SigningKeyID)
+	}
+	if !key.CanSign() {
+		return nil, fmt.Errorf("signing key %q has no private key", cfg.SigningKeyID)
+	}
+
+	return &Issuer{
+		issuer:    cfg.Issuer,
+		accessTTL: cfg.AccessTTL,
+		key:       key,
+		keys:      keys,
+	}, nil
+}
+
+// IssueAccessToken 为用户的会话签发访问令牌，实现 domain.TokenIssuer
+func (i *Issuer) IssueAccessToken(user *domain.User, sessionID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(i.accessTTL)
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    i.issuer,
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		SessionID: sessionID,
+		Username:  user.Username,
+	}
+
+	t := jwt.NewWithClaims(i.key.method, claims)
+	t.Header["kid"] = i.key.ID
+	signed, err := t.SignedString(i.key.signKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("sign access token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
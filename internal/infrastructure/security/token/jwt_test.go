@@ -0,0 +1,90 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-protos/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssuer_EdDSA(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "jwt.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	issuer, err := NewIssuer(Config{
+		Issuer:       "go-protos",
+		SigningKeyID: "ed-1",
+		Keys:         []KeyConfig{{ID: "ed-1", Algorithm: AlgorithmEdDSA, PrivateKeyFile: keyFile}},
+	})
+	require.NoError(t, err)
+
+	signed, expiresAt, err := issuer.IssueAccessToken(&domain.User{ID: "u-1", Username: "alice"}, "s-1")
+	require.NoError(t, err)
+
+	var claims Claims
+	parsed, err := jwt.ParseWithClaims(signed, &claims, func(t *jwt.Token) (any, error) {
+		return publicKey, nil
+	}, jwt.WithValidMethods([]string{"EdDSA"}))
+	require.NoError(t, err)
+	assert.Equal(t, "ed-1", parsed.Header["kid"])
+	assert.Equal(t, "u-1", claims.Subject)
+	assert.Equal(t, "s-1", claims.SessionID)
+	assert.Equal(t, expiresAt.Unix(), claims.ExpiresAt.Unix())
+}
+
+func TestNewIssuer_InvalidKeys(t *testing.T) {
+	_, err := NewIssuer(Config{
+		SigningKeyID: "short",
+		Keys:         []KeyConfig{{ID: "short", Algorithm: AlgorithmHS256, Secret: "too-short"}},
+	})
+	assert.ErrorContains(t, err, "at least 32 bytes")
+
+	_, err = NewIssuer(Config{
+		SigningKeyID: "missing",
+		Keys:         []KeyConfig{{ID: "hs", Algorithm: AlgorithmHS256, Secret: "0123456789abcdef0123456789abcdef"}},
+	})
+	assert.ErrorContains(t, err, "not configured")
+}
+
+func TestVerifier_KeyRotation(t *testing.T) {
+	oldKey := KeyConfig{ID: "2024", Algorithm: AlgorithmHS256, Secret: "old-secret-0123456789abcdef0123456789"}
+	newKey := KeyConfig{ID: "2025", Algorithm: AlgorithmHS256, Secret: "new-secret-0123456789abcdef0123456789"}
+	user := &domain.User{ID: "u-1", Username: "alice"}
+
+	oldIssuer, err := NewIssuer(Config{Issuer: "go-protos", SigningKeyID: "2024", Keys: []KeyConfig{oldKey}})
+	require.NoError(t, err)
+	oldToken, _, err := oldIssuer.IssueAccessToken(user, "s-1")
+	require.NoError(t, err)
+
+	// 轮换期间新旧密钥都可校验
+	verifier, err := NewVerifier(Config{Issuer: "go-protos", Keys: []KeyConfig{oldKey, newKey}})
+	require.NoError(t, err)
+	claims, err := verifier.Verify(oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "u-1", claims.Subject)
+
+	// 旧密钥下线后旧令牌失效
+	verifier, err = NewVerifier(Config{Issuer: "go-protos", Keys: []KeyConfig{newKey}})
+	require.NoError(t, err)
+	_, err = verifier.Verify(oldToken)
+	assert.Error(t, err)
+
+	// 签发方不匹配
+	verifier, err = NewVerifier(Config{Issuer: "other", Keys: []KeyConfig{oldKey}})
+	require.NoError(t, err)
+	_, err = verifier.Verify(oldToken)
+	assert.Error(t, err)
+}
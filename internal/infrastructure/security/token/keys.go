@@ -0,0 +1,158 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minHMACSecretLength HS256 共享密钥最小长度
+const minHMACSecretLength = 32
+
+// KeyConfig 签名密钥配置
+type KeyConfig struct {
+	ID             string // 写入 JWT 头的 kid
+	Algorithm      string // HS256 / EdDSA
+	Secret         string // HS256 共享密钥，至少32字节
+	PrivateKeyFile string // EdDSA 私钥文件（PKCS#8 PEM），用于签发
+	PublicKeyFile  string // EdDSA 公钥文件（PKIX PEM），只校验不签发时可只配置公钥
+}
+
+// Key 已加载的密钥
+type Key struct {
+	ID        string
+	Algorithm string
+	method    jwt.SigningMethod
+	signKey   any // []byte 或 ed25519.PrivateKey，为空表示只能用于校验
+	verifyKey any // []byte 或 ed25519.PublicKey
+}
+
+// CanSign 密钥是否可用于签发
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// KeySet 按 kid 索引的密钥集合，轮换时新旧密钥同时存在
+type KeySet struct {
+	keys map[string]*Key
+}
+
+// NewKeySet 加载所有配置的密钥
+func NewKeySet(configs []KeyConfig) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key, len(configs))}
+	for _, cfg := range configs {
+		if _, ok := set.keys[cfg.ID]; ok {
+			return nil, fmt.Errorf("duplicate token key id %q", cfg.ID)
+		}
+		key, err := loadKey(cfg)
+		if err != nil {
+			return nil, err
+		}
+		set.keys[cfg.ID] = key
+	}
+	return set, nil
+}
+
+// Get 根据 kid 查找密钥
+func (s *KeySet) Get(id string) (*Key, bool) {
+	key, ok := s.keys[id]
+	return key, ok
+}
+
+// loadKey 根据配置加载单个密钥
+func loadKey(cfg KeyConfig) (*Key, error) {
+	if cfg.ID == "" {
+		return nil, fmt.Errorf("token key id is required")
+	}
+
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		if len(cfg.Secret) < minHMACSecretLength {
+			return nil, fmt.Errorf("token key %q: HS256 secret must be at least %d bytes", cfg.ID, minHMACSecretLength)
+		}
+		secret := []byte(cfg.Secret)
+		return &Key{ID: cfg.ID, Algorithm: cfg.Algorithm, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+
+	case AlgorithmEdDSA:
+		key := &Key{ID: cfg.ID, Algorithm: cfg.Algorithm, method: jwt.SigningMethodEdDSA}
+		if cfg.PrivateKeyFile != "" {
+			privateKey, err := readEd25519PrivateKey(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("token key %q: %w", cfg.ID, err)
+			}
+			key.signKey = privateKey
+			key.verifyKey = privateKey.Public()
+		}
+		if cfg.PublicKeyFile != "" {
+			publicKey, err := readEd25519PublicKey(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("token key %q: %w", cfg.ID, err)
+			}
+			key.verifyKey = publicKey
+		}
+		if key.verifyKey == nil {
+			return nil, fmt.Errorf("token key %q: EdDSA requires private_key_file or public_key_file", cfg.ID)
+		}
+		return key, nil
+
+	default:
+		return nil, fmt.Errorf("token key %q: unsupported algorithm %q", cfg.ID, cfg.Algorithm)
+	}
+}
+
+// readEd25519PrivateKey 读取 PKCS#8 PEM 格式的 Ed25519 私钥
+func readEd25519PrivateKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key %s: %w", path, err)
+	}
+	privateKey, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is not an Ed25519 key", path)
+	}
+	return privateKey, nil
+}
+
+// readEd25519PublicKey 读取 PKIX PEM 格式的 Ed25519 公钥
+func readEd25519PublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key %s: %w", path, err)
+	}
+	publicKey, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an Ed25519 key", path)
+	}
+	return publicKey, nil
+}
+
+// readPEM 读取文件中的第一个 PEM 块
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	return block, nil
+}
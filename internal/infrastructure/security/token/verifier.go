@@ -0,0 +1,64 @@
+package token
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// defaultLeeway 校验时间类声明时允许的时钟偏差
+const defaultLeeway = 30 * time.Second
+
+// Verifier JWT 访问令牌校验器，按 kid 在密钥集合中选择密钥，支持密钥轮换
+type Verifier struct {
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+// NewVerifier 根据配置创建校验器，配置中的所有密钥（包括只有公钥的）都可用于校验
+func NewVerifier(cfg Config) (*Verifier, error) {
+	keys, err := NewKeySet(cfg.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(defaultLeeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	return &Verifier{
+		keys:   keys,
+		parser: jwt.NewParser(opts...),
+	}, nil
+}
+
+// Verify 校验访问令牌的签名和有效期，返回其中的声明
+func (v *Verifier) Verify(tokenString string) (*Claims, error) {
+	var claims Claims
+	_, err := v.parser.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := v.keys.Get(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		// 防止用其他算法的密钥伪造签名
+		if t.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("key %q does not accept algorithm %s", kid, t.Method.Alg())
+		}
+		return key.verifyKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+	return &claims, nil
+}
@@ -0,0 +1,101 @@
+package telemetry
+
+import (
+	"errors"
+
+	"go-protos/pkg/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// 语句 span 及其操作类型在 gorm 实例中的键
+const (
+	spanKey      = "telemetry:span"
+	operationKey = "telemetry:operation"
+)
+
+// gormPlugin 为每条语句创建 DB span 的 GORM 插件
+type gormPlugin struct {
+	tracer trace.Tracer
+}
+
+// InstrumentGORM 为数据库连接注册链路追踪回调，span 以语句类型和表名命名，如 "query users"
+func InstrumentGORM(db *gorm.DB) error {
+	return db.Use(&gormPlugin{tracer: otel.Tracer(tracing.InstrumentationName)})
+}
+
+// Name 插件名称
+func (p *gormPlugin) Name() string {
+	return "telemetry"
+}
+
+// Initialize 在各类操作前后注册回调
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("telemetry:before_create", p.before("create")),
+		cb.Create().After("*").Register("telemetry:after_create", p.after),
+		cb.Query().Before("*").Register("telemetry:before_query", p.before("query")),
+		cb.Query().After("*").Register("telemetry:after_query", p.after),
+		cb.Update().Before("*").Register("telemetry:before_update", p.before("update")),
+		cb.Update().After("*").Register("telemetry:after_update", p.after),
+		cb.Delete().Before("*").Register("telemetry:before_delete", p.before("delete")),
+		cb.Delete().After("*").Register("telemetry:after_delete", p.after),
+		cb.Row().Before("*").Register("telemetry:before_row", p.before("row")),
+		cb.Row().After("*").Register("telemetry:after_row", p.after),
+		cb.Raw().Before("*").Register("telemetry:before_raw", p.before("raw")),
+		cb.Raw().After("*").Register("telemetry:after_raw", p.after),
+	)
+}
+
+// before 以语句上下文为父 span 开始 DB span；语句未携带上下文（如未调用 WithContext）时跳过
+func (p *gormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			return
+		}
+		ctx, span := p.tracer.Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameKey.String(db.Dialector.Name()),
+				semconv.DBOperationNameKey.String(operation),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+		db.InstanceSet(operationKey, operation)
+	}
+}
+
+// after 记录表名、语句文本（参数以占位符表示）和影响行数后结束 span
+func (p *gormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if table := db.Statement.Table; table != "" {
+		if operation, ok := db.InstanceGet(operationKey); ok {
+			span.SetName(operation.(string) + " " + table)
+		}
+		span.SetAttributes(semconv.DBCollectionNameKey.String(table))
+	}
+	span.SetAttributes(
+		semconv.DBQueryTextKey.String(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	// 驱动返回的错误可能带有违反唯一约束的邮箱等取值，经 RecordError 脱敏
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		tracing.RecordError(span, db.Error)
+	}
+}
@@ -0,0 +1,128 @@
+package telemetry
+
+import (
+	"context"
+
+	"go-protos/internal/domain"
+	"go-protos/pkg/tracing"
+)
+
+// TraceUserRepository 包装用户仓储，为每次调用创建子 span
+func TraceUserRepository(repo domain.UserRepository) domain.UserRepository {
+	return &tracedUserRepository{next: repo}
+}
+
+// tracedUserRepository 带链路追踪的用户仓储
+type tracedUserRepository struct {
+	next domain.UserRepository
+}
+
+func (r *tracedUserRepository) FindByUsername(ctx context.Context, username string) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.FindByUsername")
+	defer tracing.End(span, &err)
+	return r.next.FindByUsername(ctx, username)
+}
+
+func (r *tracedUserRepository) FindById(ctx context.Context, id string) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.FindById")
+	defer tracing.End(span, &err)
+	return r.next.FindById(ctx, id)
+}
+
+func (r *tracedUserRepository) FindByEmail(ctx context.Context, email string) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.FindByEmail")
+	defer tracing.End(span, &err)
+	return r.next.FindByEmail(ctx, email)
+}
+
+func (r *tracedUserRepository) FindByIdUnscoped(ctx context.Context, id string) (_ *domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.FindByIdUnscoped")
+	defer tracing.End(span, &err)
+	return r.next.FindByIdUnscoped(ctx, id)
+}
+
+func (r *tracedUserRepository) Save(ctx context.Context, user *domain.User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Save")
+	defer tracing.End(span, &err)
+	return r.next.Save(ctx, user)
+}
+
+func (r *tracedUserRepository) List(ctx context.Context, opts domain.UserListOptions) (_ []*domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.List")
+	defer tracing.End(span, &err)
+	return r.next.List(ctx, opts)
+}
+
+func (r *tracedUserRepository) FindByUsernamesOrEmails(ctx context.Context, usernames, emails []string, includeDeleted bool) (_ []*domain.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.FindByUsernamesOrEmails")
+	defer tracing.End(span, &err)
+	return r.next.FindByUsernamesOrEmails(ctx, usernames, emails, includeDeleted)
+}
+
+func (r *tracedUserRepository) SaveBatch(ctx context.Context, users []*domain.User) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.SaveBatch")
+	defer tracing.End(span, &err)
+	return r.next.SaveBatch(ctx, users)
+}
+
+func (r *tracedUserRepository) Purge(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Purge")
+	defer tracing.End(span, &err)
+	return r.next.Purge(ctx, id)
+}
+
+// TraceUserRoleRepository 包装用户角色仓储，为每次调用创建子 span
+func TraceUserRoleRepository(repo domain.UserRoleRepository) domain.UserRoleRepository {
+	return &tracedUserRoleRepository{next: repo}
+}
+
+// tracedUserRoleRepository 带链路追踪的用户角色仓储
+type tracedUserRoleRepository struct {
+	next domain.UserRoleRepository
+}
+
+func (r *tracedUserRoleRepository) ListRoles(ctx context.Context, userID string) (_ []domain.Role, err error) {
+	ctx, span := tracing.Start(ctx, "UserRoleRepository.ListRoles")
+	defer tracing.End(span, &err)
+	return r.next.ListRoles(ctx, userID)
+}
+
+func (r *tracedUserRoleRepository) AddRole(ctx context.Context, userID string, role domain.Role) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRoleRepository.AddRole")
+	defer tracing.End(span, &err)
+	return r.next.AddRole(ctx, userID, role)
+}
+
+func (r *tracedUserRoleRepository) RemoveRole(ctx context.Context, userID string, role domain.Role) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRoleRepository.RemoveRole")
+	defer tracing.End(span, &err)
+	return r.next.RemoveRole(ctx, userID, role)
+}
+
+// TraceSessionRepository 包装会话仓储，为每次调用创建子 span
+func TraceSessionRepository(repo domain.SessionRepository) domain.SessionRepository {
+	return &tracedSessionRepository{next: repo}
+}
+
+// tracedSessionRepository 带链路追踪的会话仓储
+type tracedSessionRepository struct {
+	next domain.SessionRepository
+}
+
+func (r *tracedSessionRepository) Save(ctx context.Context, session *domain.Session) (err error) {
+	ctx, span := tracing.Start(ctx, "SessionRepository.Save")
+	defer tracing.End(span, &err)
+	return r.next.Save(ctx, session)
+}
+
+func (r *tracedSessionRepository) SaveRotated(ctx context.Context, session *domain.Session) (err error) {
+	ctx, span := tracing.Start(ctx, "SessionRepository.SaveRotated")
+	defer tracing.End(span, &err)
+	return r.next.SaveRotated(ctx, session)
+}
+
+func (r *tracedSessionRepository) FindById(ctx context.Context, id string) (_ *domain.Session, err error) {
+	ctx, span := tracing.Start(ctx, "SessionRepository.FindById")
+	defer tracing.End(span, &err)
+	return r.next.FindById(ctx, id)
+}
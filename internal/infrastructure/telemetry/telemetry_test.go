@@ -0,0 +1,142 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-protos/internal/application/commands"
+	"go-protos/internal/application/queries"
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/eventbus"
+	"go-protos/internal/infrastructure/persistence/sqlite"
+	"go-protos/internal/infrastructure/security/password"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	sqlitedriver "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupRecorder 将全局 TracerProvider 替换为内存记录器，测试结束后恢复
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestCreateUserSpans(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	db, err := gorm.Open(sqlitedriver.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.User{}))
+	require.NoError(t, InstrumentGORM(db))
+
+	hasher, err := password.New(password.Config{Algorithm: password.AlgorithmBcrypt, BcryptCost: 4})
+	require.NoError(t, err)
+	repo := TraceUserRepository(sqlite.NewUserRepository(db))
+	handler := commands.NewCreateUserCommandHandler(repo, domain.NewUserDomainService(repo, domain.ReuseAfterPurge), hasher, eventbus.NewUserEventLog(0))
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "CreateUser")
+	_, err = handler.Handle(ctx, commands.CreateUserCommand{Username: "alice", Email: "alice@example.com", Password: "password123"})
+	require.NoError(t, err)
+	root.End()
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, root.SpanContext().TraceID(), span.SpanContext().TraceID(), span.Name())
+		if _, ok := spans[span.Name()]; !ok {
+			spans[span.Name()] = span
+		}
+	}
+	require.Contains(t, spans, "CreateUserCommandHandler.Handle")
+	require.Contains(t, spans, "UserRepository.Save")
+	table := domain.User{}.TableName()
+	require.Contains(t, spans, "create "+table)
+
+	handle := spans["CreateUserCommandHandler.Handle"]
+	save := spans["UserRepository.Save"]
+	insert := spans["create "+table]
+	assert.Equal(t, root.SpanContext().SpanID(), handle.Parent().SpanID())
+	assert.Equal(t, handle.SpanContext().SpanID(), save.Parent().SpanID())
+	assert.Equal(t, save.SpanContext().SpanID(), insert.Parent().SpanID())
+	assert.Contains(t, insert.Attributes(), semconv.DBCollectionNameKey.String(table))
+
+	// 唯一性检查未找到记录属于正常结果，不标记为错误
+	for _, span := range recorder.Ended() {
+		assert.NotEqual(t, codes.Error, span.Status().Code, span.Name())
+	}
+}
+
+func TestHandleSpanRecordsError(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	hasher, err := password.New(password.Config{Algorithm: password.AlgorithmBcrypt, BcryptCost: 4})
+	require.NoError(t, err)
+	db, err := gorm.Open(sqlitedriver.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.User{}))
+	repo := sqlite.NewUserRepository(db)
+	handler := commands.NewCreateUserCommandHandler(repo, domain.NewUserDomainService(repo, domain.ReuseAfterPurge), hasher, eventbus.NewUserEventLog(0))
+
+	_, err = handler.Handle(context.Background(), commands.CreateUserCommand{Username: "bob", Email: "not-an-email", Password: "password123"})
+	require.ErrorIs(t, err, domain.ErrInvalidEmail)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "CreateUserCommandHandler.Handle", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestHandleSpanNotFound(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	db, err := gorm.Open(sqlitedriver.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&domain.User{}))
+	handler := queries.NewGetUserByIdQueryHandler(TraceUserRepository(sqlite.NewUserRepository(db)))
+
+	_, err = handler.Handle(context.Background(), queries.GetUserByIdQuery{UserID: "missing"})
+	require.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	// 与仓储 span 一致，未找到不标记为错误
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		assert.NotEqual(t, codes.Error, span.Status().Code, span.Name())
+	}
+}
+
+func TestSetup_FileExporter(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	file := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown, err := Setup(context.Background(), Config{ServiceName: "go-protos", Exporter: ExporterFile, File: file, SampleRatio: 1})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "local-run")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"local-run"`)
+	assert.Contains(t, string(data), `"Value":"go-protos"`)
+
+	_, err = Setup(context.Background(), Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/logging"
+	"go-protos/internal/infrastructure/security/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// authorizationKey Bearer 访问令牌所在的元数据键
+	authorizationKey = "authorization"
+	// apiKeyKey 服务账号API密钥所在的元数据键
+	apiKeyKey = "x-api-key"
+)
+
+// DefaultPublicMethods 默认无需认证的方法：健康检查、反射和登录相关接口
+// 可在配置的 protected_methods 中列出以覆盖
+var DefaultPublicMethods = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+	"/auth.v1.AuthService/Authenticate",
+	"/auth.v1.AuthService/Refresh",
+	"/auth.v1.AuthService/Logout",
+}
+
+// AuthInterceptor 认证拦截器：受保护的方法必须携带有效的 Bearer 访问令牌或API密钥，
+// 认证通过后调用方通过 domain.PrincipalFromContext 传递给处理器和应用层
+// 分为两步：Unary/Stream 解析凭证，EnforceUnary/EnforceStream 拒绝未认证的调用；
+// 限流位于两者之间，凭证无效的调用按IP消耗令牌，猜测API密钥或令牌同样受限
+type AuthInterceptor struct {
+	authenticator *auth.Authenticator
+	policy        *auth.Policy
+}
+
+// authFailureKey 上下文中凭证解析失败的原因
+type authFailureKey struct{}
+
+// NewAuthInterceptor 创建认证拦截器
+func NewAuthInterceptor(authenticator *auth.Authenticator, policy *auth.Policy) *AuthInterceptor {
+	return &AuthInterceptor{
+		authenticator: authenticator,
+		policy:        policy,
+	}
+}
+
+// Unary 一元RPC凭证解析拦截器，不拒绝调用
+func (i *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(i.authenticate(ctx), req)
+	}
+}
+
+// Stream 流式RPC凭证解析拦截器，不拒绝调用
+func (i *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: i.authenticate(ss.Context())})
+	}
+}
+
+// EnforceUnary 一元RPC认证检查，受保护的方法没有有效凭证时返回 Unauthenticated
+func (i *AuthInterceptor) EnforceUnary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := i.enforce(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// EnforceStream 流式RPC认证检查
+func (i *AuthInterceptor) EnforceStream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := i.enforce(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authenticate 解析凭证，成功时将调用方写入上下文，失败时记录原因
+// 公开方法的凭证是可选的：有效时同样传递调用方，缺失或无效时按匿名调用处理
+func (i *AuthInterceptor) authenticate(ctx context.Context) context.Context {
+	principal, err := i.principal(ctx)
+	if err != nil {
+		return context.WithValue(ctx, authFailureKey{}, err)
+	}
+	// 让外层访问日志也能记录调用方
+	logging.SetPrincipal(ctx, principal)
+	return domain.ContextWithPrincipal(ctx, principal)
+}
+
+// enforce 受保护的方法必须已认证
+func (i *AuthInterceptor) enforce(ctx context.Context, method string) error {
+	if i.policy.IsPublic(method) {
+		return nil
+	}
+	if _, ok := domain.PrincipalFromContext(ctx); ok {
+		return nil
+	}
+	err, _ := ctx.Value(authFailureKey{}).(error)
+	if err == nil {
+		err = domain.ErrMissingCredentials
+	}
+	return toStatusError(err, "")
+}
+
+// principal 从元数据中的 Bearer 访问令牌或API密钥解析调用方
+func (i *AuthInterceptor) principal(ctx context.Context) (*domain.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	switch {
+	case len(md.Get(authorizationKey)) > 0:
+		scheme, credentials, ok := strings.Cut(md.Get(authorizationKey)[0], " ")
+		if !ok || !strings.EqualFold(scheme, "bearer") || credentials == "" {
+			return nil, domain.ErrInvalidAccessToken
+		}
+		return i.authenticator.AuthenticateBearer(credentials)
+	case len(md.Get(apiKeyKey)) > 0:
+		return i.authenticator.AuthenticateAPIKey(md.Get(apiKeyKey)[0])
+	default:
+		return nil, domain.ErrMissingCredentials
+	}
+}
@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+
+	"go-protos/internal/application"
+	"go-protos/internal/application/commands"
+	"go-protos/proto/authpb"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AuthGrpcService 认证gRPC服务实现
+type AuthGrpcService struct {
+	authpb.UnimplementedAuthServiceServer
+	authService *application.AuthAppService
+}
+
+// NewAuthGrpcService 创建认证gRPC服务
+func NewAuthGrpcService(authService *application.AuthAppService) *AuthGrpcService {
+	return &AuthGrpcService{
+		authService: authService,
+	}
+}
+
+// Authenticate 登录
+func (s *AuthGrpcService) Authenticate(ctx context.Context, req *authpb.AuthenticateRequest) (*authpb.AuthenticateResponse, error) {
+	tokens, err := s.authService.Authenticate(ctx, req.UsernameOrEmail, req.Password)
+	if err != nil {
+		return nil, toStatusError(err, "")
+	}
+
+	return &authpb.AuthenticateResponse{
+		Tokens: toProtoTokenPair(tokens),
+		UserId: tokens.UserID,
+	}, nil
+}
+
+// Refresh 刷新令牌
+func (s *AuthGrpcService) Refresh(ctx context.Context, req *authpb.RefreshRequest) (*authpb.RefreshResponse, error) {
+	tokens, err := s.authService.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, toStatusError(err, "")
+	}
+
+	return &authpb.RefreshResponse{
+		Tokens: toProtoTokenPair(tokens),
+	}, nil
+}
+
+// Logout 退出登录
+func (s *AuthGrpcService) Logout(ctx context.Context, req *authpb.LogoutRequest) (*emptypb.Empty, error) {
+	if err := s.authService.Logout(ctx, req.RefreshToken); err != nil {
+		return nil, toStatusError(err, "")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// toProtoTokenPair 将令牌对转换为protobuf消息
+func toProtoTokenPair(t *commands.AuthTokens) *authpb.TokenPair {
+	return &authpb.TokenPair{
+		AccessToken:           t.AccessToken,
+		TokenType:             "Bearer",
+		AccessTokenExpiresAt:  timestamppb.New(t.AccessTokenExpiresAt),
+		RefreshToken:          t.RefreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(t.RefreshTokenExpiresAt),
+	}
+}
@@ -0,0 +1,116 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go-protos/internal/domain"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const (
+	// errorDomain ErrorInfo 中的错误域
+	errorDomain = "go-protos"
+	// userResourceType ResourceInfo 中的资源类型
+	userResourceType = "user.v1.User"
+)
+
+// toStatusError 将应用层返回的错误转换为带错误详情的gRPC状态错误
+// resource 为本次请求涉及的资源标识（如用户ID、用户名），可为空
+func toStatusError(err error, resource string) error {
+	if err == nil {
+		return nil
+	}
+
+	// 已经是gRPC状态错误的直接透传
+	if st, ok := status.FromError(err); ok && st.Code() != codes.Unknown {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		// 未知错误不向客户端暴露内部细节
+		return withDetails(status.New(codes.Internal, "internal error"), errorInfo("INTERNAL", ""))
+	}
+
+	info := errorInfo(domainErr.Reason, domainErr.Field)
+	switch domainErr.Kind {
+	case domain.KindNotFound:
+		return withDetails(status.New(codes.NotFound, domainErr.Error()), info, resourceInfo(resource, domainErr))
+	case domain.KindAlreadyExists:
+		return withDetails(status.New(codes.AlreadyExists, domainErr.Error()), info, resourceInfo(resource, domainErr))
+	case domain.KindInvalidArgument:
+		badRequest := &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: domainErr.Field, Description: domainErr.Error()},
+			},
+		}
+		return withDetails(status.New(codes.InvalidArgument, domainErr.Error()), info, badRequest)
+	case domain.KindOutOfRange:
+		return withDetails(status.New(codes.OutOfRange, domainErr.Error()), info)
+	case domain.KindUnauthenticated:
+		return withDetails(status.New(codes.Unauthenticated, domainErr.Error()), info)
+	case domain.KindPermissionDenied:
+		return withDetails(status.New(codes.PermissionDenied, domainErr.Error()), info, resourceInfo(resource, domainErr))
+	default:
+		return withDetails(status.New(codes.Internal, "internal error"), info)
+	}
+}
+
+// invalidArgument 构造接口层参数校验失败的状态错误
+func invalidArgument(field, description string) error {
+	return fieldViolations(&errdetails.BadRequest_FieldViolation{Field: field, Description: description})
+}
+
+// fieldViolations 构造包含多个字段校验失败的状态错误
+func fieldViolations(violations ...*errdetails.BadRequest_FieldViolation) error {
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.Field+" "+v.Description)
+	}
+	badRequest := &errdetails.BadRequest{FieldViolations: violations}
+	st := status.New(codes.InvalidArgument, strings.Join(messages, "; "))
+	return withDetails(st, errorInfo("INVALID_ARGUMENT", violations[0].Field), badRequest)
+}
+
+// errorInfo 构造带稳定原因码的 ErrorInfo
+func errorInfo(reason, field string) *errdetails.ErrorInfo {
+	info := &errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: errorDomain,
+	}
+	if field != "" {
+		info.Metadata = map[string]string{"field": field}
+	}
+	return info
+}
+
+// resourceInfo 构造 ResourceInfo
+func resourceInfo(resource string, err *domain.Error) *errdetails.ResourceInfo {
+	return &errdetails.ResourceInfo{
+		ResourceType: userResourceType,
+		ResourceName: resource,
+		Description:  err.Error(),
+	}
+}
+
+// withDetails 附加错误详情，附加失败时退化为无详情的状态错误
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
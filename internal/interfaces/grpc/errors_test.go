@@ -0,0 +1,77 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go-protos/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatusError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{"not found", domain.ErrUserNotFound, codes.NotFound, "USER_NOT_FOUND"},
+		{"wrapped not found", fmt.Errorf("lookup: %w", domain.ErrUserNotFound), codes.NotFound, "USER_NOT_FOUND"},
+		{"username exists", domain.ErrUsernameExists, codes.AlreadyExists, "USERNAME_EXISTS"},
+		{"email exists", domain.ErrEmailExists, codes.AlreadyExists, "EMAIL_EXISTS"},
+		{"invalid email", domain.ErrInvalidEmail, codes.InvalidArgument, "INVALID_EMAIL"},
+		{"unknown", errors.New("db down"), codes.Internal, "INTERNAL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(toStatusError(tt.err, "alice"))
+			assert.Equal(t, tt.code, st.Code())
+
+			var info *errdetails.ErrorInfo
+			for _, d := range st.Details() {
+				if v, ok := d.(*errdetails.ErrorInfo); ok {
+					info = v
+				}
+			}
+			require.NotNil(t, info)
+			assert.Equal(t, tt.reason, info.Reason)
+			assert.Equal(t, errorDomain, info.Domain)
+		})
+	}
+}
+
+func TestToStatusErrorDetails(t *testing.T) {
+	st := status.Convert(toStatusError(domain.ErrInvalidEmail, ""))
+	var badRequest *errdetails.BadRequest
+	for _, d := range st.Details() {
+		if v, ok := d.(*errdetails.BadRequest); ok {
+			badRequest = v
+		}
+	}
+	require.NotNil(t, badRequest)
+	require.Len(t, badRequest.FieldViolations, 1)
+	assert.Equal(t, "email", badRequest.FieldViolations[0].Field)
+
+	st = status.Convert(toStatusError(domain.ErrUserNotFound, "u-1"))
+	var resource *errdetails.ResourceInfo
+	for _, d := range st.Details() {
+		if v, ok := d.(*errdetails.ResourceInfo); ok {
+			resource = v
+		}
+	}
+	require.NotNil(t, resource)
+	assert.Equal(t, "u-1", resource.ResourceName)
+
+	// 内部错误不泄露细节，状态错误与上下文错误直接映射
+	assert.Equal(t, "internal error", status.Convert(toStatusError(errors.New("secret dsn"), "")).Message())
+	assert.Equal(t, codes.Unavailable, status.Code(toStatusError(status.Error(codes.Unavailable, "x"), "")))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(toStatusError(context.DeadlineExceeded, "")))
+}
@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go-protos/proto/authpb"
+	"go-protos/proto/userpb"
+	"go-protos/proto/userv2pb"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+)
+
+// Pinger 依赖连通性探测，*sql.DB 满足该接口
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// HealthChecker 周期性探测依赖，并据此更新 grpc.health.v1 中各服务的状态
+type HealthChecker struct {
+	server   *health.Server
+	pinger   Pinger
+	interval time.Duration
+	timeout  time.Duration
+	// services 依赖数据库的服务，"" 表示整体状态
+	services []string
+
+	mu      sync.Mutex
+	serving bool
+}
+
+// NewHealthChecker 创建健康检查器，interval、timeout 为零时使用默认值
+// 首次探测完成前所有服务均为 NOT_SERVING
+func NewHealthChecker(pinger Pinger, interval, timeout time.Duration) *HealthChecker {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	h := &HealthChecker{
+		server:   health.NewServer(),
+		pinger:   pinger,
+		interval: interval,
+		timeout:  timeout,
+		services: []string{
+			"",
+			userpb.UserService_ServiceDesc.ServiceName,
+			userv2pb.UserService_ServiceDesc.ServiceName,
+			authpb.AuthService_ServiceDesc.ServiceName,
+			authpb.RoleService_ServiceDesc.ServiceName,
+		},
+	}
+	h.setServing(false)
+	return h
+}
+
+// Server 返回 grpc.health.v1.Health 服务实现（支持 Check 和 Watch）
+func (h *HealthChecker) Server() healthpb.HealthServer {
+	return h.server
+}
+
+// Run 立即探测一次，之后按间隔周期探测，直到 ctx 结束
+func (h *HealthChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		h.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check 执行一次依赖探测并更新服务状态
+func (h *HealthChecker) Check(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	err := h.pinger.PingContext(pingCtx)
+	if ctx.Err() != nil {
+		// 停止过程中的探测失败不代表依赖不可用
+		return
+	}
+
+	h.mu.Lock()
+	changed := h.serving != (err == nil)
+	h.mu.Unlock()
+	if changed {
+		if err != nil {
+			slog.WarnContext(ctx, "health check failed, marking services NOT_SERVING", slog.Any("error", err))
+		} else {
+			slog.InfoContext(ctx, "health check passed, marking services SERVING")
+		}
+	}
+	h.setServing(err == nil)
+}
+
+// Shutdown 将所有服务标记为 NOT_SERVING，且之后不再接受状态更新
+// 应在停止接收请求前调用，使负载均衡器尽早摘除实例
+func (h *HealthChecker) Shutdown() {
+	h.server.Shutdown()
+}
+
+// setServing 更新所有受检服务的状态
+func (h *HealthChecker) setServing(serving bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.serving = serving
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	for _, service := range h.services {
+		h.server.SetServingStatus(service, status)
+	}
+}
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-protos/proto/userpb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type fakePinger struct {
+	err error
+}
+
+func (p *fakePinger) PingContext(ctx context.Context) error {
+	return p.err
+}
+
+func TestHealthChecker(t *testing.T) {
+	ctx := context.Background()
+	pinger := &fakePinger{}
+	checker := NewHealthChecker(pinger, 0, 0)
+
+	statusOf := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := checker.Server().Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.Status
+	}
+	userService := userpb.UserService_ServiceDesc.ServiceName
+
+	// 首次探测前不对外提供服务
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(""))
+
+	checker.Check(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, statusOf(userService))
+
+	pinger.err = errors.New("connection refused")
+	checker.Check(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(userService))
+
+	// 停止后即使探测成功也保持 NOT_SERVING
+	pinger.err = nil
+	checker.Shutdown()
+	checker.Check(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, statusOf(""))
+}
@@ -0,0 +1,92 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-protos/internal/infrastructure/ratelimit"
+	"go-protos/pkg/requestid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecoveryUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetUserById"}
+	_, err := RecoveryUnaryInterceptor()(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestRequestIDUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetUserById"}
+	var got string
+	handler := func(ctx context.Context, req any) (any, error) {
+		got = requestid.FromContext(ctx)
+		return nil, nil
+	}
+
+	// 传入的请求ID被沿用
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, "req-123"))
+	_, _ = RequestIDUnaryInterceptor()(ctx, nil, info, handler)
+	assert.Equal(t, "req-123", got)
+
+	// 缺失时自动生成
+	_, _ = RequestIDUnaryInterceptor()(context.Background(), nil, info, handler)
+	assert.NotEmpty(t, got)
+	assert.NotEqual(t, "req-123", got)
+}
+
+func TestRateLimitUnaryInterceptor(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), []ratelimit.Rule{
+		{Match: "/user.v1.UserService/CreateUser", KeyBy: ratelimit.KeyByIP, Limit: ratelimit.Limit{Rate: 1, Burst: 1}},
+	}, nil)
+	require.NoError(t, err)
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/CreateUser"}
+	handler := func(ctx context.Context, req any) (any, error) { return nil, nil }
+
+	_, err = RateLimitUnaryInterceptor(limiter)(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+
+	_, err = RateLimitUnaryInterceptor(limiter)(context.Background(), nil, info, handler)
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	var retryInfo *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		if ri, ok := d.(*errdetails.RetryInfo); ok {
+			retryInfo = ri
+		}
+	}
+	require.NotNil(t, retryInfo)
+	assert.Positive(t, retryInfo.RetryDelay.AsDuration())
+
+	// 未配置限流器时不限流
+	_, err = RateLimitUnaryInterceptor(nil)(context.Background(), nil, info, handler)
+	assert.NoError(t, err)
+}
+
+func TestDeadlineUnaryInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.v1.UserService/GetUserById"}
+	var deadline time.Time
+	handler := func(ctx context.Context, req any) (any, error) {
+		deadline, _ = ctx.Deadline()
+		return nil, nil
+	}
+
+	// 客户端未设置时使用默认时限
+	_, _ = DeadlineUnaryInterceptor(time.Minute)(context.Background(), nil, info, handler)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+
+	// 客户端设置的截止时间优先
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	_, _ = DeadlineUnaryInterceptor(time.Minute)(ctx, nil, info, handler)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Second)
+}
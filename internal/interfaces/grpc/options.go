@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-protos/config"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// serverOptions 根据配置生成连接、消息大小和保活相关的服务器选项
+func serverOptions(cfg *config.GRPCConfig) ([]grpc.ServerOption, error) {
+	durations := map[string]string{
+		"read_timeout":                       cfg.ReadTimeout,
+		"keepalive.time":                     cfg.Keepalive.Time,
+		"keepalive.timeout":                  cfg.Keepalive.Timeout,
+		"keepalive.min_time":                 cfg.Keepalive.MinTime,
+		"keepalive.max_connection_idle":      cfg.Keepalive.MaxConnectionIdle,
+		"keepalive.max_connection_age":       cfg.Keepalive.MaxConnectionAge,
+		"keepalive.max_connection_age_grace": cfg.Keepalive.MaxConnectionAgeGrace,
+	}
+	parsed := make(map[string]time.Duration, len(durations))
+	for key, value := range durations {
+		d, err := parseDuration(key, value)
+		if err != nil {
+			return nil, err
+		}
+		parsed[key] = d
+	}
+
+	var opts []grpc.ServerOption
+	if d := parsed["read_timeout"]; d > 0 {
+		opts = append(opts, grpc.ConnectionTimeout(d))
+	}
+	if cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	if cfg.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(cfg.MaxConcurrentStreams))
+	}
+	opts = append(opts,
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:                  parsed["keepalive.time"],
+			Timeout:               parsed["keepalive.timeout"],
+			MaxConnectionIdle:     parsed["keepalive.max_connection_idle"],
+			MaxConnectionAge:      parsed["keepalive.max_connection_age"],
+			MaxConnectionAgeGrace: parsed["keepalive.max_connection_age_grace"],
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             parsed["keepalive.min_time"],
+			PermitWithoutStream: cfg.Keepalive.PermitWithoutStream,
+		}),
+	)
+	return opts, nil
+}
+
+// parseDuration 解析时长配置，空值表示 0（不限制）
+func parseDuration(key, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid grpc %s %q: %w", key, value, err)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid grpc %s %q: must not be negative", key, value)
+	}
+	return d, nil
+}
+
+// DeadlineUnaryInterceptor 客户端未设置截止时间时为一元RPC设置默认时限，d 为 0 时不设置
+// 流式RPC（如 WatchUsers）通常是长连接，不使用默认时限
+func DeadlineUnaryInterceptor(d time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := ctx.Deadline(); ok || d <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
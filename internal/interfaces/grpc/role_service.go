@@ -0,0 +1,54 @@
+package grpc
+
+import (
+	"context"
+
+	"go-protos/internal/application"
+	"go-protos/internal/domain"
+	"go-protos/proto/authpb"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// RoleGrpcService 角色管理gRPC服务实现
+type RoleGrpcService struct {
+	authpb.UnimplementedRoleServiceServer
+	appService *application.UserAppService
+}
+
+// NewRoleGrpcService 创建角色管理gRPC服务
+func NewRoleGrpcService(appService *application.UserAppService) *RoleGrpcService {
+	return &RoleGrpcService{
+		appService: appService,
+	}
+}
+
+// ListUserRoles 列出用户角色
+func (s *RoleGrpcService) ListUserRoles(ctx context.Context, req *authpb.ListUserRolesRequest) (*authpb.ListUserRolesResponse, error) {
+	roles, err := s.appService.ListUserRoles(ctx, req.UserId)
+	if err != nil {
+		return nil, toStatusError(err, req.UserId)
+	}
+
+	resp := &authpb.ListUserRolesResponse{Roles: make([]string, 0, len(roles))}
+	for _, role := range roles {
+		resp.Roles = append(resp.Roles, string(role))
+	}
+	return resp, nil
+}
+
+// GrantUserRole 授予用户角色
+func (s *RoleGrpcService) GrantUserRole(ctx context.Context, req *authpb.GrantUserRoleRequest) (*emptypb.Empty, error) {
+	if err := s.appService.GrantUserRole(ctx, req.UserId, domain.Role(req.Role)); err != nil {
+		return nil, toStatusError(err, req.UserId)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// RevokeUserRole 撤销用户角色
+func (s *RoleGrpcService) RevokeUserRole(ctx context.Context, req *authpb.RevokeUserRoleRequest) (*emptypb.Empty, error) {
+	if err := s.appService.RevokeUserRole(ctx, req.UserId, domain.Role(req.Role)); err != nil {
+		return nil, toStatusError(err, req.UserId)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -1,40 +1,121 @@
 package grpc
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"log/slog"
 	"net"
 
+	"go-protos/config"
 	"go-protos/internal/application"
+	"go-protos/internal/infrastructure/metrics"
+	"go-protos/internal/infrastructure/ratelimit"
+	"go-protos/proto/authpb"
 	"go-protos/proto/userpb"
+	"go-protos/proto/userv2pb"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 // Server gRPC服务器
 type Server struct {
-	grpcServer  *grpc.Server
-	userService *UserGrpcService
+	grpcServer    *grpc.Server
+	userService   *UserGrpcService
+	userServiceV2 *UserGrpcServiceV2
+	authService   *AuthGrpcService
+	roleService   *RoleGrpcService
+	health        *HealthChecker
+	cancelStreams context.CancelFunc // 停止时取消服务端流式RPC
 }
 
-// NewServer 创建gRPC服务器
-func NewServer(appService *application.UserAppService) *Server {
-	// 创建gRPC服务器
-	grpcServer := grpc.NewServer()
+// NewServer 创建gRPC服务器，cfg 中的时长配置不合法时返回错误；tlsConfig 为空时使用明文连接
+func NewServer(
+	cfg *config.GRPCConfig,
+	tlsConfig *tls.Config,
+	appService *application.UserAppService,
+	authAppService *application.AuthAppService,
+	healthChecker *HealthChecker,
+	authInterceptor *AuthInterceptor,
+	rateLimiter *ratelimit.Limiter,
+	m *metrics.Metrics,
+) (*Server, error) {
+	opts, err := serverOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defaultDeadline, err := parseDuration("default_deadline", cfg.DefaultDeadline)
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	// 链路追踪在拦截器之前建立 span，访问日志等拦截器可从上下文读取 trace ID
+	opts = append(opts, tracingOption())
+
+	streamCtx, cancelStreams := context.WithCancel(context.Background())
 
-	// 创建用户服务
+	// 创建gRPC服务器，拦截器顺序：默认截止时间 -> 请求ID -> 客户端证书身份 -> 访问日志 -> 指标 -> panic恢复 -> 解析凭证 -> 限流 -> 拒绝未认证调用
+	// 流式RPC另在最外层接入停止时的取消
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(
+			DeadlineUnaryInterceptor(defaultDeadline),
+			RequestIDUnaryInterceptor(),
+			ClientIdentityUnaryInterceptor(),
+			AccessLogUnaryInterceptor(),
+			MetricsUnaryInterceptor(m),
+			RecoveryUnaryInterceptor(),
+			authInterceptor.Unary(),
+			RateLimitUnaryInterceptor(rateLimiter),
+			authInterceptor.EnforceUnary(),
+		),
+		grpc.ChainStreamInterceptor(
+			ShutdownStreamInterceptor(streamCtx),
+			RequestIDStreamInterceptor(),
+			ClientIdentityStreamInterceptor(),
+			AccessLogStreamInterceptor(),
+			MetricsStreamInterceptor(m),
+			RecoveryStreamInterceptor(),
+			authInterceptor.Stream(),
+			RateLimitStreamInterceptor(rateLimiter),
+			authInterceptor.EnforceStream(),
+		),
+	)
+	grpcServer := grpc.NewServer(opts...)
+
+	// 创建用户服务，v1 和 v2 共用同一个应用服务
 	userService := NewUserGrpcService(appService)
+	userServiceV2 := NewUserGrpcServiceV2(appService)
+	authService := NewAuthGrpcService(authAppService)
+	roleService := NewRoleGrpcService(appService)
 
 	// 注册服务
 	userpb.RegisterUserServiceServer(grpcServer, userService)
+	userv2pb.RegisterUserServiceServer(grpcServer, userServiceV2)
+	authpb.RegisterAuthServiceServer(grpcServer, authService)
+	authpb.RegisterRoleServiceServer(grpcServer, roleService)
+	healthpb.RegisterHealthServer(grpcServer, healthChecker.Server())
 
-	// 启用反射（方便调试）
-	reflection.Register(grpcServer)
+	// 按配置启用反射（方便调试，生产环境建议关闭）
+	if cfg.Reflection {
+		reflection.Register(grpcServer)
+	}
 
 	return &Server{
-		grpcServer:  grpcServer,
-		userService: userService,
-	}
+		grpcServer:    grpcServer,
+		userService:   userService,
+		userServiceV2: userServiceV2,
+		authService:   authService,
+		roleService:   roleService,
+		health:        healthChecker,
+		cancelStreams: cancelStreams,
+	}, nil
 }
 
 // Start 启动gRPC服务器
@@ -44,7 +125,7 @@ func (s *Server) Start(addr string) error {
 		return fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
 
-	fmt.Printf("gRPC server starting on %s\n", addr)
+	slog.Info("grpc server starting", slog.String("addr", addr))
 
 	if err := s.grpcServer.Serve(lis); err != nil {
 		return fmt.Errorf("failed to serve gRPC server: %w", err)
@@ -55,6 +136,28 @@ func (s *Server) Start(addr string) error {
 
 // Stop 优雅停止gRPC服务器
 func (s *Server) Stop() {
-	fmt.Println("Stopping gRPC server...")
+	slog.Info("stopping grpc server")
+	// 先标记为 NOT_SERVING，健康检查的 Watch 流会收到状态变更
+	s.health.Shutdown()
+	// 订阅类的流不会自行结束，取消后 GracefulStop 只需等待其余请求
+	s.cancelStreams()
 	s.grpcServer.GracefulStop()
 }
+
+// Shutdown 优雅停止gRPC服务器，ctx 结束时仍未排空则强制关闭剩余连接
+func (s *Server) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		<-done
+		return fmt.Errorf("graceful stop interrupted: %w", ctx.Err())
+	}
+}
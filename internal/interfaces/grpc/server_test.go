@@ -0,0 +1,385 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"go-protos/config"
+	"go-protos/internal/application"
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/eventbus"
+	"go-protos/internal/infrastructure/persistence/inmem"
+	"go-protos/internal/infrastructure/ratelimit"
+	"go-protos/internal/infrastructure/security/auth"
+	"go-protos/internal/infrastructure/security/password"
+	"go-protos/internal/infrastructure/security/token"
+	"go-protos/proto/authpb"
+	"go-protos/proto/userpb"
+	"go-protos/proto/userv2pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// testTokenKeys 测试使用的签名密钥
+var testTokenKeys = []token.KeyConfig{{ID: "test", Algorithm: token.AlgorithmHS256, Secret: "test-secret-0123456789abcdef0123456789"}}
+
+// testAPIKey 测试使用的服务账号API密钥，拥有全部权限
+const testAPIKey = "test-api-key"
+
+// newTestConn 启动基于内存仓储和 bufconn 的完整服务器，返回客户端连接
+// policy 为空时所有方法都无需认证
+func newTestConn(t *testing.T, policy *auth.Policy) *grpc.ClientConn {
+	t.Helper()
+	_, conn := newTestServer(t, policy, nil)
+	return conn
+}
+
+// newTestServer 同 newTestConn，可指定限流器，同时返回服务器以便测试停止流程
+func newTestServer(t *testing.T, policy *auth.Policy, limiter *ratelimit.Limiter) (*Server, *grpc.ClientConn) {
+	t.Helper()
+	if policy == nil {
+		policy = auth.NewPolicy(true, nil, nil)
+	}
+	hasher, err := password.New(password.Config{Algorithm: password.AlgorithmBcrypt, BcryptCost: 4})
+	require.NoError(t, err)
+	repo := inmem.NewInMemoryUserRepository()
+	appService := application.NewUserAppService(repo, repo.Roles(), domain.NewUserDomainService(repo, domain.ReuseAfterPurge), hasher, eventbus.NewUserEventLog(0))
+	tokenIssuer, err := token.NewIssuer(token.Config{SigningKeyID: "test", Keys: testTokenKeys})
+	require.NoError(t, err)
+	tokenVerifier, err := token.NewVerifier(token.Config{Keys: testTokenKeys})
+	require.NoError(t, err)
+	scopes := make([]string, 0, len(domain.AllPermissions))
+	for _, perm := range domain.AllPermissions {
+		scopes = append(scopes, string(perm))
+	}
+	authenticator, err := auth.NewAuthenticator(tokenVerifier, []auth.APIKey{
+		{Name: "test-service", SHA256: auth.HashAPIKey(testAPIKey), Scopes: scopes},
+	})
+	require.NoError(t, err)
+	authAppService := application.NewAuthAppService(repo, repo.Sessions(), hasher, tokenIssuer, time.Hour)
+	server, err := NewServer(&config.GRPCConfig{Reflection: true, DefaultDeadline: "5s"}, nil, appService, authAppService, NewHealthChecker(&fakePinger{}, 0, 0), NewAuthInterceptor(authenticator, policy), limiter, nil)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	go server.grpcServer.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return server, conn
+}
+
+func TestServer_V1AndV2ShareAppService(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", testAPIKey)
+	conn := newTestConn(t, nil)
+	v1 := userpb.NewUserServiceClient(conn)
+	v2 := userv2pb.NewUserServiceClient(conn)
+
+	// v1 创建的用户可以通过 v2 读取
+	created, err := v1.CreateUser(ctx, &userpb.CreateUserRequest{Username: "alice", Email: "alice@example.com", Password: "correct-horse"})
+	require.NoError(t, err)
+
+	got, err := v2.GetUser(ctx, &userv2pb.GetUserRequest{Id: created.User.Id})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.Username)
+	assert.False(t, got.CreatedAt.AsTime().IsZero())
+
+	// v2 更新直接返回变更后的资源
+	updated, err := v2.UpdateUser(ctx, &userv2pb.UpdateUserRequest{
+		User:       &userv2pb.User{Id: created.User.Id, Email: "alice2@example.com"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice2@example.com", updated.Email)
+
+	_, err = v2.GetUserByUsername(ctx, &userv2pb.GetUserByUsernameRequest{Username: "bob"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestServer_AuthService(t *testing.T) {
+	ctx := context.Background()
+	conn := newTestConn(t, nil)
+	users := userpb.NewUserServiceClient(conn)
+	auth := authpb.NewAuthServiceClient(conn)
+
+	serviceCtx := metadata.AppendToOutgoingContext(ctx, "x-api-key", testAPIKey)
+	_, err := users.CreateUser(serviceCtx, &userpb.CreateUserRequest{Username: "alice", Email: "alice@example.com", Password: "correct-horse"})
+	require.NoError(t, err)
+
+	_, err = auth.Authenticate(ctx, &authpb.AuthenticateRequest{UsernameOrEmail: "alice", Password: "wrong-password"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	login, err := auth.Authenticate(ctx, &authpb.AuthenticateRequest{UsernameOrEmail: "alice@example.com", Password: "correct-horse"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", login.Tokens.TokenType)
+	assert.NotEmpty(t, login.Tokens.AccessToken)
+
+	// 刷新后旧的刷新令牌失效，再次使用旧令牌视为泄露，整个会话被撤销
+	refreshed, err := auth.Refresh(ctx, &authpb.RefreshRequest{RefreshToken: login.Tokens.RefreshToken})
+	require.NoError(t, err)
+	_, err = auth.Refresh(ctx, &authpb.RefreshRequest{RefreshToken: login.Tokens.RefreshToken})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = auth.Refresh(ctx, &authpb.RefreshRequest{RefreshToken: refreshed.Tokens.RefreshToken})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// 退出后刷新令牌失效
+	login, err = auth.Authenticate(ctx, &authpb.AuthenticateRequest{UsernameOrEmail: "alice", Password: "correct-horse"})
+	require.NoError(t, err)
+	refreshed, err = auth.Refresh(ctx, &authpb.RefreshRequest{RefreshToken: login.Tokens.RefreshToken})
+	require.NoError(t, err)
+	_, err = auth.Logout(ctx, &authpb.LogoutRequest{RefreshToken: refreshed.Tokens.RefreshToken})
+	require.NoError(t, err)
+	_, err = auth.Refresh(ctx, &authpb.RefreshRequest{RefreshToken: refreshed.Tokens.RefreshToken})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// 彻底清除用户后其会话一并删除，即使同名用户重新注册也无法刷新
+	login, err = auth.Authenticate(ctx, &authpb.AuthenticateRequest{UsernameOrEmail: "alice", Password: "correct-horse"})
+	require.NoError(t, err)
+	_, err = users.PurgeUser(serviceCtx, &userpb.PurgeUserRequest{UserId: login.UserId})
+	require.NoError(t, err)
+	_, err = users.CreateUser(serviceCtx, &userpb.CreateUserRequest{Username: "alice", Email: "alice@example.com", Password: "correct-horse"})
+	require.NoError(t, err)
+	_, err = auth.Refresh(ctx, &authpb.RefreshRequest{RefreshToken: login.Tokens.RefreshToken})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestServer_Authentication(t *testing.T) {
+	ctx := context.Background()
+	conn := newTestConn(t, auth.NewPolicy(false, DefaultPublicMethods, nil))
+	users := userpb.NewUserServiceClient(conn)
+
+	// 健康检查默认公开
+	_, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	_, err = users.GetUserById(ctx, &userpb.GetUserByIdRequest{Id: "u-1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	badToken := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer not-a-jwt")
+	_, err = users.GetUserById(badToken, &userpb.GetUserByIdRequest{Id: "u-1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// API密钥认证的服务账号创建用户，随后用户登录并使用访问令牌
+	serviceCtx := metadata.AppendToOutgoingContext(ctx, "x-api-key", testAPIKey)
+	created, err := users.CreateUser(serviceCtx, &userpb.CreateUserRequest{Username: "alice", Email: "alice@example.com", Password: "correct-horse"})
+	require.NoError(t, err)
+
+	login, err := authpb.NewAuthServiceClient(conn).Authenticate(ctx, &authpb.AuthenticateRequest{UsernameOrEmail: "alice", Password: "correct-horse"})
+	require.NoError(t, err)
+	userCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+login.Tokens.AccessToken)
+	got, err := users.GetUserById(userCtx, &userpb.GetUserByIdRequest{Id: created.User.Id})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.User.Username)
+}
+
+func TestServer_RateLimitInvalidCredentials(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), nil,
+		&ratelimit.Rule{Match: "*", KeyBy: ratelimit.KeyByPrincipal, Limit: ratelimit.Limit{Rate: 0.1, Burst: 3}})
+	require.NoError(t, err)
+	_, conn := newTestServer(t, auth.NewPolicy(false, DefaultPublicMethods, nil), limiter)
+	users := userpb.NewUserServiceClient(conn)
+
+	// 猜测API密钥的调用在被拒绝前按IP消耗令牌
+	var got []codes.Code
+	for i := 0; i < 4; i++ {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", fmt.Sprintf("guess-%d", i))
+		_, err := users.GetUserById(ctx, &userpb.GetUserByIdRequest{Id: "u-1"})
+		got = append(got, status.Code(err))
+	}
+	assert.Equal(t, []codes.Code{codes.Unauthenticated, codes.Unauthenticated, codes.Unauthenticated, codes.ResourceExhausted}, got)
+}
+
+func TestServer_Authorization(t *testing.T) {
+	ctx := context.Background()
+	conn := newTestConn(t, auth.NewPolicy(false, DefaultPublicMethods, nil))
+	users := userpb.NewUserServiceClient(conn)
+	roles := authpb.NewRoleServiceClient(conn)
+
+	serviceCtx := metadata.AppendToOutgoingContext(ctx, "x-api-key", testAPIKey)
+	alice, err := users.CreateUser(serviceCtx, &userpb.CreateUserRequest{Username: "alice", Email: "alice@example.com", Password: "correct-horse"})
+	require.NoError(t, err)
+	bob, err := users.CreateUser(serviceCtx, &userpb.CreateUserRequest{Username: "bob", Email: "bob@example.com", Password: "correct-horse"})
+	require.NoError(t, err)
+
+	login, err := authpb.NewAuthServiceClient(conn).Authenticate(ctx, &authpb.AuthenticateRequest{UsernameOrEmail: "alice", Password: "correct-horse"})
+	require.NoError(t, err)
+	aliceCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+login.Tokens.AccessToken)
+
+	// 自助用户只能读取和修改自己
+	_, err = users.GetUserByUsername(aliceCtx, &userpb.GetUserByUsernameRequest{Username: "alice"})
+	require.NoError(t, err)
+	_, err = users.UpdateUserEmail(aliceCtx, &userpb.UpdateUserEmailRequest{UserId: alice.User.Id, Email: "alice2@example.com"})
+	require.NoError(t, err)
+	_, err = users.GetUserById(aliceCtx, &userpb.GetUserByIdRequest{Id: bob.User.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = users.GetUserByEmail(aliceCtx, &userpb.GetUserByEmailRequest{Email: "bob@example.com"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = users.DeleteUser(aliceCtx, &userpb.DeleteUserRequest{UserId: alice.User.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = roles.GrantUserRole(aliceCtx, &authpb.GrantUserRoleRequest{UserId: alice.User.Id, Role: "admin"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// 授予管理员后可以读取任意用户，角色在下一次调用时生效
+	_, err = roles.GrantUserRole(serviceCtx, &authpb.GrantUserRoleRequest{UserId: alice.User.Id, Role: "superuser"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = roles.GrantUserRole(serviceCtx, &authpb.GrantUserRoleRequest{UserId: alice.User.Id, Role: "admin"})
+	require.NoError(t, err)
+	listed, err := roles.ListUserRoles(aliceCtx, &authpb.ListUserRolesRequest{UserId: alice.User.Id})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user", "admin"}, listed.Roles)
+	_, err = users.GetUserById(aliceCtx, &userpb.GetUserByIdRequest{Id: bob.User.Id})
+	require.NoError(t, err)
+
+	_, err = roles.RevokeUserRole(aliceCtx, &authpb.RevokeUserRoleRequest{UserId: alice.User.Id, Role: "admin"})
+	require.NoError(t, err)
+	_, err = users.GetUserById(aliceCtx, &userpb.GetUserByIdRequest{Id: bob.User.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServer_ImportUsers(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", testAPIKey)
+	users := userpb.NewUserServiceClient(newTestConn(t, nil))
+
+	// 逐条结果超过上限时截断，汇总计数仍包含全部记录
+	stream, err := users.ImportUsers(ctx)
+	require.NoError(t, err)
+	invalid := make([]*userpb.ImportUserRecord, 1000)
+	for i := range invalid {
+		invalid[i] = &userpb.ImportUserRecord{Email: "not-an-email"}
+	}
+	for range maxImportResults/len(invalid) + 1 {
+		require.NoError(t, stream.Send(&userpb.ImportUsersRequest{Users: invalid}))
+	}
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(maxImportResults+len(invalid)), resp.Invalid)
+	assert.Len(t, resp.Results, maxImportResults)
+	assert.True(t, resp.ResultsTruncated)
+
+	// 某一批失败时，错误详情中包含之前已提交批次的汇总
+	stream, err = users.ImportUsers(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&userpb.ImportUsersRequest{Users: []*userpb.ImportUserRecord{
+		{Username: "alice", Email: "alice@example.com", Password: "correct-horse"},
+	}}))
+	require.NoError(t, stream.Send(&userpb.ImportUsersRequest{Users: append(invalid, invalid[0])}))
+	_, err = stream.CloseAndRecv()
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	var partial *userpb.ImportUsersResponse
+	for _, detail := range st.Details() {
+		if d, ok := detail.(*userpb.ImportUsersResponse); ok {
+			partial = d
+		}
+	}
+	require.NotNil(t, partial, "partial summary missing from error details")
+	assert.Equal(t, uint32(1), partial.Created)
+}
+
+func TestServer_ShutdownWithOpenWatch(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", testAPIKey)
+	server, conn := newTestServer(t, nil, nil)
+	users := userpb.NewUserServiceClient(conn)
+
+	for _, name := range []string{"alice", "bob"} {
+		_, err := users.CreateUser(ctx, &userpb.CreateUserRequest{Username: name, Email: name + "@example.com", Password: "correct-horse"})
+		require.NoError(t, err)
+	}
+
+	// 收到回放的事件说明订阅已在服务端运行
+	watch, err := users.WatchUsers(ctx, &userpb.WatchUsersRequest{AfterSequence: 1})
+	require.NoError(t, err)
+	_, err = watch.Recv()
+	require.NoError(t, err)
+	health, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = health.Recv()
+	require.NoError(t, err)
+
+	// 打开的订阅不应拖到停止时限
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	started := time.Now()
+	require.NoError(t, server.Shutdown(shutdownCtx))
+	assert.Less(t, time.Since(started), time.Second)
+
+	_, err = watch.Recv()
+	assert.Error(t, err)
+}
+
+func TestNewServer_Config(t *testing.T) {
+	health := NewHealthChecker(&fakePinger{}, 0, 0)
+
+	server, err := NewServer(&config.GRPCConfig{Reflection: false}, nil, nil, nil, health, nil, nil, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, server.grpcServer.GetServiceInfo(), "grpc.reflection.v1.ServerReflection")
+	assert.Contains(t, server.grpcServer.GetServiceInfo(), "user.v1.UserService")
+
+	server, err = NewServer(&config.GRPCConfig{Reflection: true}, nil, nil, nil, health, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Contains(t, server.grpcServer.GetServiceInfo(), "grpc.reflection.v1.ServerReflection")
+
+	_, err = NewServer(&config.GRPCConfig{Keepalive: config.GRPCKeepaliveConfig{MaxConnectionAge: "soon"}}, nil, nil, nil, health, nil, nil, nil)
+	assert.ErrorContains(t, err, "keepalive.max_connection_age")
+}
+
+func TestServer_TracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	conn := newTestConn(t, nil)
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		"x-api-key", testAPIKey,
+		"traceparent", "00-"+traceID+"-00f067aa0ba902b7-01",
+	)
+	_, err := userpb.NewUserServiceClient(conn).CreateUser(ctx, &userpb.CreateUserRequest{Username: "alice", Email: "alice@example.com", Password: "correct-horse"})
+	require.NoError(t, err)
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	server, ok := spans["user.v1.UserService/CreateUser"]
+	require.True(t, ok, "server span not recorded")
+	assert.Equal(t, traceID, server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.True(t, server.Parent().IsRemote())
+
+	handle, ok := spans["CreateUserCommandHandler.Handle"]
+	require.True(t, ok, "handler span not recorded")
+	assert.Equal(t, server.SpanContext().SpanID(), handle.Parent().SpanID())
+
+	// 健康检查不创建 span
+	assert.NotContains(t, spans, "grpc.health.v1.Health/Check")
+}
@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"context"
+
+	"go-protos/internal/infrastructure/security/tlsconfig"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientIdentityUnaryInterceptor 将通过校验的客户端证书身份写入上下文，
+// 处理器通过 tlsconfig.ClientIdentityFromContext 读取
+func ClientIdentityUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withClientIdentity(ctx), req)
+	}
+}
+
+// ClientIdentityStreamInterceptor 流式RPC的客户端证书身份拦截器
+func ClientIdentityStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: withClientIdentity(ss.Context())})
+	}
+}
+
+// withClientIdentity 从连接的 TLS 状态中提取客户端身份，非 TLS 连接或没有客户端证书时原样返回
+func withClientIdentity(ctx context.Context) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ctx
+	}
+	if identity := tlsconfig.IdentityFromConnectionState(&tlsInfo.State); identity != nil {
+		return tlsconfig.ContextWithClientIdentity(ctx, identity)
+	}
+	return ctx
+}
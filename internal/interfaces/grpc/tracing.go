@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+)
+
+// untracedServices 不创建 span 的服务：健康检查和反射调用频繁且没有排查价值
+var untracedServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// tracingOption 为每次RPC创建服务端 span，并从传入元数据中提取 W3C Trace Context 作为父 span；
+// 使用全局 TracerProvider，未启用链路追踪时为空操作
+func tracingOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler(
+		otelgrpc.WithFilter(func(info *stats.RPCTagInfo) bool {
+			for _, prefix := range untracedServices {
+				if strings.HasPrefix(info.FullMethodName, prefix) {
+					return false
+				}
+			}
+			return true
+		}),
+	))
+}
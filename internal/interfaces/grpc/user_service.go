@@ -2,12 +2,19 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"go-protos/internal/application"
+	"go-protos/internal/application/commands"
+	"go-protos/internal/application/queries"
 	"go-protos/internal/domain"
 	"go-protos/proto/userpb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
 )
 
 // UserGrpcService 用户gRPC服务实现
@@ -25,15 +32,11 @@ func NewUserGrpcService(appService *application.UserAppService) *UserGrpcService
 
 // GetUserByUsername 根据用户名获取用户
 func (s *UserGrpcService) GetUserByUsername(ctx context.Context, req *userpb.GetUserByUsernameRequest) (*userpb.GetUserByUsernameResponse, error) {
-	fmt.Printf("gRPC GetUserByUsername called with username: %s\n", req.Username)
-
 	user, err := s.appService.GetUserByUsername(ctx, req.Username)
 	if err != nil {
-		fmt.Printf("gRPC GetUserByUsername failed: %v\n", err)
-		return nil, err
+		return nil, toStatusError(err, req.Username)
 	}
 
-	fmt.Printf("gRPC GetUserByUsername success for user: %s\n", req.Username)
 	return &userpb.GetUserByUsernameResponse{
 		User: toProtoUser(user),
 	}, nil
@@ -41,31 +44,35 @@ func (s *UserGrpcService) GetUserByUsername(ctx context.Context, req *userpb.Get
 
 // GetUserById 根据ID获取用户
 func (s *UserGrpcService) GetUserById(ctx context.Context, req *userpb.GetUserByIdRequest) (*userpb.GetUserByIdResponse, error) {
-	fmt.Printf("gRPC GetUserById called with id: %s\n", req.Id)
-
 	user, err := s.appService.GetUserById(ctx, req.Id)
 	if err != nil {
-		fmt.Printf("gRPC GetUserById failed: %v\n", err)
-		return nil, err
+		return nil, toStatusError(err, req.Id)
 	}
 
-	fmt.Printf("gRPC GetUserById success for user: %s\n", req.Id)
 	return &userpb.GetUserByIdResponse{
 		User: toProtoUser(user),
 	}, nil
 }
 
+// GetUserByEmail 根据邮箱获取用户
+func (s *UserGrpcService) GetUserByEmail(ctx context.Context, req *userpb.GetUserByEmailRequest) (*userpb.GetUserByEmailResponse, error) {
+	user, err := s.appService.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, toStatusError(err, req.Email)
+	}
+
+	return &userpb.GetUserByEmailResponse{
+		User: toProtoUser(user),
+	}, nil
+}
+
 // CreateUser 创建用户
 func (s *UserGrpcService) CreateUser(ctx context.Context, req *userpb.CreateUserRequest) (*userpb.CreateUserResponse, error) {
-	fmt.Printf("gRPC CreateUser called with username: %s, email: %s\n", req.Username, req.Email)
-
-	user, err := s.appService.CreateUser(ctx, req.Username, req.Email, req.PasswordHash)
+	user, err := s.appService.CreateUser(ctx, req.Username, req.Email, req.Password)
 	if err != nil {
-		fmt.Printf("gRPC CreateUser failed: %v\n", err)
-		return nil, err
+		return nil, toStatusError(err, req.Username)
 	}
 
-	fmt.Printf("gRPC CreateUser success for user: %s\n", req.Username)
 	return &userpb.CreateUserResponse{
 		User: toProtoUser(user),
 	}, nil
@@ -73,31 +80,331 @@ func (s *UserGrpcService) CreateUser(ctx context.Context, req *userpb.CreateUser
 
 // UpdateUserEmail 更新用户邮箱
 func (s *UserGrpcService) UpdateUserEmail(ctx context.Context, req *userpb.UpdateUserEmailRequest) (*userpb.UpdateUserEmailResponse, error) {
-	fmt.Printf("gRPC UpdateUserEmail called with userID: %s, email: %s\n", req.UserId, req.Email)
-
 	err := s.appService.UpdateUserEmail(ctx, req.UserId, req.Email)
 	if err != nil {
-		fmt.Printf("gRPC UpdateUserEmail failed: %v\n", err)
-		return nil, err
+		return nil, toStatusError(err, req.UserId)
 	}
 
-	fmt.Printf("gRPC UpdateUserEmail success for user: %s\n", req.UserId)
 	return &userpb.UpdateUserEmailResponse{
 		Success: true,
 	}, nil
 }
 
+// UpdateUserPassword 更新用户密码
+func (s *UserGrpcService) UpdateUserPassword(ctx context.Context, req *userpb.UpdateUserPasswordRequest) (*userpb.UpdateUserPasswordResponse, error) {
+	err := s.appService.UpdateUserPassword(ctx, req.UserId, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		return nil, toStatusError(err, req.UserId)
+	}
+
+	return &userpb.UpdateUserPasswordResponse{
+		Success: true,
+	}, nil
+}
+
+// UpdateUser 按字段掩码部分更新用户
+func (s *UserGrpcService) UpdateUser(ctx context.Context, req *userpb.UpdateUserRequest) (*userpb.UpdateUserResponse, error) {
+	if req.User.GetId() == "" {
+		return nil, invalidArgument("user.id", "is required")
+	}
+	fields, violations := userUpdateFields(req.User.Username, req.User.Email, req.UpdateMask.GetPaths())
+	if len(violations) > 0 {
+		return nil, fieldViolations(violations...)
+	}
+
+	user, err := s.appService.UpdateUser(ctx, commands.UpdateUserCommand{
+		UserID:   req.User.Id,
+		Username: req.User.Username,
+		Email:    req.User.Email,
+		Fields:   fields,
+	})
+	if err != nil {
+		return nil, toStatusError(err, req.User.Id)
+	}
+
+	return &userpb.UpdateUserResponse{
+		User: toProtoUser(user),
+	}, nil
+}
+
+// ListUsers 分页列出用户
+func (s *UserGrpcService) ListUsers(ctx context.Context, req *userpb.ListUsersRequest) (*userpb.ListUsersResponse, error) {
+	filter := domain.UserListFilter{
+		EmailDomain:    req.EmailDomain,
+		UsernamePrefix: req.UsernamePrefix,
+	}
+	var err error
+	if filter.CreatedAfter, err = parseTimestamp(req.CreatedAfter); err != nil {
+		return nil, invalidArgument("created_after", "must be an RFC3339 timestamp")
+	}
+	if filter.CreatedBefore, err = parseTimestamp(req.CreatedBefore); err != nil {
+		return nil, invalidArgument("created_before", "must be an RFC3339 timestamp")
+	}
+
+	result, err := s.appService.ListUsers(ctx, queries.ListUsersQuery{
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+		OrderBy:   req.OrderBy,
+		Filter:    filter,
+	})
+	if err != nil {
+		return nil, toStatusError(err, "")
+	}
+
+	users := make([]*userpb.User, 0, len(result.Users))
+	for _, u := range result.Users {
+		users = append(users, toProtoUser(u))
+	}
+	return &userpb.ListUsersResponse{
+		Users:         users,
+		NextPageToken: result.NextPageToken,
+	}, nil
+}
+
+// WatchUsers 推送用户变更事件
+func (s *UserGrpcService) WatchUsers(req *userpb.WatchUsersRequest, stream userpb.UserService_WatchUsersServer) error {
+	err := s.appService.WatchUsers(stream.Context(), req.AfterSequence, func(event domain.UserEvent) error {
+		return stream.Send(toProtoUserEvent(event))
+	})
+	return toStatusError(err, "")
+}
+
+// maxImportResults 导入响应中逐条结果的上限，避免大批量导入时响应超过消息大小限制
+const maxImportResults = 10000
+
+// ImportUsers 批量导入用户，每收到一条消息处理一批；某一批失败时在错误详情中返回已处理批次的汇总
+func (s *UserGrpcService) ImportUsers(stream userpb.UserService_ImportUsersServer) error {
+	ctx := stream.Context()
+
+	var (
+		session       *commands.UserImportSession
+		reportCreated bool
+		resp          = &userpb.ImportUsersResponse{}
+	)
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// 导入选项以第一条消息为准
+		if session == nil {
+			session, err = s.appService.StartUserImport(ctx, req.DryRun)
+			if err != nil {
+				return toStatusError(err, "")
+			}
+			reportCreated = req.ReportCreated
+		}
+
+		records := make([]commands.ImportUserRecord, 0, len(req.Users))
+		for _, u := range req.Users {
+			records = append(records, commands.ImportUserRecord{
+				Username: u.Username,
+				Email:    u.Email,
+				Password: u.Password,
+			})
+		}
+
+		batchResults, err := session.ImportBatch(ctx, records)
+		if err != nil {
+			setImportSummary(resp, session.Summary())
+			return importFailure(err, resp)
+		}
+		for _, r := range batchResults {
+			if r.Status == commands.ImportCreated && !reportCreated {
+				continue
+			}
+			if len(resp.Results) >= maxImportResults {
+				resp.ResultsTruncated = true
+				continue
+			}
+			resp.Results = append(resp.Results, toProtoImportResult(r))
+		}
+	}
+
+	if session == nil {
+		var err error
+		if session, err = s.appService.StartUserImport(ctx, false); err != nil {
+			return toStatusError(err, "")
+		}
+	}
+	setImportSummary(resp, session.Summary())
+	return stream.SendAndClose(resp)
+}
+
+// setImportSummary 写入导入汇总计数
+func setImportSummary(resp *userpb.ImportUsersResponse, summary commands.ImportUsersSummary) {
+	resp.DryRun = summary.DryRun
+	resp.Created = uint32(summary.Created)
+	resp.Duplicates = uint32(summary.Duplicates)
+	resp.Invalid = uint32(summary.Invalid)
+}
+
+// importFailure 转换批次错误，并附加失败前已处理批次的汇总；之前的批次已提交，客户端据此决定从哪一批继续
+func importFailure(err error, partial *userpb.ImportUsersResponse) error {
+	st, _ := status.FromError(toStatusError(err, ""))
+	return withDetails(st, partial)
+}
+
+// DeleteUser 软删除用户
+func (s *UserGrpcService) DeleteUser(ctx context.Context, req *userpb.DeleteUserRequest) (*userpb.DeleteUserResponse, error) {
+	if err := s.appService.DeleteUser(ctx, req.UserId); err != nil {
+		return nil, toStatusError(err, req.UserId)
+	}
+
+	return &userpb.DeleteUserResponse{
+		Success: true,
+	}, nil
+}
+
+// RestoreUser 恢复已软删除的用户
+func (s *UserGrpcService) RestoreUser(ctx context.Context, req *userpb.RestoreUserRequest) (*userpb.RestoreUserResponse, error) {
+	user, err := s.appService.RestoreUser(ctx, req.UserId)
+	if err != nil {
+		return nil, toStatusError(err, req.UserId)
+	}
+
+	return &userpb.RestoreUserResponse{
+		User: toProtoUser(user),
+	}, nil
+}
+
+// PurgeUser 彻底清除用户（管理接口）
+func (s *UserGrpcService) PurgeUser(ctx context.Context, req *userpb.PurgeUserRequest) (*userpb.PurgeUserResponse, error) {
+	if err := s.appService.PurgeUser(ctx, req.UserId); err != nil {
+		return nil, toStatusError(err, req.UserId)
+	}
+
+	return &userpb.PurgeUserResponse{
+		Success: true,
+	}, nil
+}
+
+// updatableUserFields update_mask 路径与可更新字段的对应关系
+var updatableUserFields = map[string]commands.UserField{
+	"username": commands.UserFieldUsername,
+	"email":    commands.UserFieldEmail,
+}
+
+// immutableUserFields 存在但不允许通过 UpdateUser 修改的字段
+var immutableUserFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// userUpdateFields 将 update_mask 路径转换为待更新字段，未知或不可变路径逐条返回字段错误
+// 掩码为空时按请求中非空的可更新字段推断
+func userUpdateFields(username, email string, paths []string) ([]commands.UserField, []*errdetails.BadRequest_FieldViolation) {
+	if len(paths) == 0 {
+		var fields []commands.UserField
+		if username != "" {
+			fields = append(fields, commands.UserFieldUsername)
+		}
+		if email != "" {
+			fields = append(fields, commands.UserFieldEmail)
+		}
+		if len(fields) == 0 {
+			return nil, []*errdetails.BadRequest_FieldViolation{
+				{Field: "update_mask", Description: "must list at least one field to update"},
+			}
+		}
+		return fields, nil
+	}
+
+	var (
+		fields     []commands.UserField
+		violations []*errdetails.BadRequest_FieldViolation
+		seen       = make(map[commands.UserField]bool)
+	)
+	for i, path := range paths {
+		field, ok := updatableUserFields[path]
+		switch {
+		case ok:
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		case immutableUserFields[path]:
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       fmt.Sprintf("update_mask.paths[%d]", i),
+				Description: fmt.Sprintf("field %q is immutable", path),
+			})
+		default:
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       fmt.Sprintf("update_mask.paths[%d]", i),
+				Description: fmt.Sprintf("unknown field %q", path),
+			})
+		}
+	}
+	return fields, violations
+}
+
+// parseTimestamp 解析可选的RFC3339时间，空字符串返回零值
+func parseTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
 // toProtoUser 将领域用户转换为protobuf用户
 func toProtoUser(u *domain.User) *userpb.User {
 	if u == nil {
 		return nil
 	}
 	return &userpb.User{
-		Id:           u.ID,
-		Username:     u.Username,
-		Email:        u.Email,
-		PasswordHash: u.PasswordHash,
-		CreatedAt:    u.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:    u.UpdatedAt.Format(time.RFC3339),
+		Id:        u.ID,
+		Username:  u.Username,
+		Email:     u.Email,
+		CreatedAt: u.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: u.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// toProtoUserEvent 将领域事件转换为protobuf事件
+func toProtoUserEvent(e domain.UserEvent) *userpb.UserEvent {
+	var eventType userpb.UserEventType
+	switch e.Type {
+	case domain.UserCreated:
+		eventType = userpb.UserEventType_USER_EVENT_TYPE_CREATED
+	case domain.UserUpdated:
+		eventType = userpb.UserEventType_USER_EVENT_TYPE_UPDATED
+	case domain.UserDeleted:
+		eventType = userpb.UserEventType_USER_EVENT_TYPE_DELETED
+	}
+	return &userpb.UserEvent{
+		Sequence:      e.Sequence,
+		Type:          eventType,
+		User:          toProtoUser(&e.User),
+		ChangedFields: e.ChangedFields,
+		OccurredAt:    e.OccurredAt.Format(time.RFC3339),
+	}
+}
+
+// toProtoImportResult 将导入结果转换为protobuf结果
+func toProtoImportResult(r commands.ImportUserResult) *userpb.ImportUserResult {
+	result := &userpb.ImportUserResult{
+		Index:    uint32(r.Index),
+		Username: r.Username,
+		UserId:   r.UserID,
+	}
+	switch r.Status {
+	case commands.ImportCreated:
+		result.Status = userpb.ImportStatus_IMPORT_STATUS_CREATED
+	case commands.ImportDuplicate:
+		result.Status = userpb.ImportStatus_IMPORT_STATUS_DUPLICATE
+	case commands.ImportInvalid:
+		result.Status = userpb.ImportStatus_IMPORT_STATUS_INVALID
+	}
+
+	var domainErr *domain.Error
+	if errors.As(r.Err, &domainErr) {
+		result.Reason = domainErr.Reason
+		result.Field = domainErr.Field
 	}
+	return result
 }
@@ -0,0 +1,33 @@
+package grpc
+
+import (
+	"testing"
+
+	"go-protos/internal/application/commands"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserUpdateFields(t *testing.T) {
+	fields, violations := userUpdateFields("", "alice@example.com", []string{"email", "username", "email"})
+	assert.Empty(t, violations)
+	assert.Equal(t, []commands.UserField{commands.UserFieldEmail, commands.UserFieldUsername}, fields)
+
+	// 掩码为空时按非空字段推断
+	fields, violations = userUpdateFields("", "alice@example.com", nil)
+	assert.Empty(t, violations)
+	assert.Equal(t, []commands.UserField{commands.UserFieldEmail}, fields)
+
+	_, violations = userUpdateFields("", "", nil)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "update_mask", violations[0].Field)
+
+	// 不可变和未知路径逐条报错
+	_, violations = userUpdateFields("", "alice@example.com", []string{"email", "id", "created_at", "password_hash"})
+	require.Len(t, violations, 3)
+	assert.Equal(t, "update_mask.paths[1]", violations[0].Field)
+	assert.Contains(t, violations[0].Description, "immutable")
+	assert.Equal(t, "update_mask.paths[3]", violations[2].Field)
+	assert.Contains(t, violations[2].Description, "unknown")
+}
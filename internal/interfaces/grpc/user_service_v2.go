@@ -0,0 +1,204 @@
+package grpc
+
+import (
+	"context"
+
+	"go-protos/internal/application"
+	"go-protos/internal/application/commands"
+	"go-protos/internal/application/queries"
+	"go-protos/internal/domain"
+	"go-protos/proto/userv2pb"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UserGrpcServiceV2 用户gRPC服务 v2 实现，与 v1 共用同一个应用服务
+type UserGrpcServiceV2 struct {
+	userv2pb.UnimplementedUserServiceServer
+	appService *application.UserAppService
+}
+
+// NewUserGrpcServiceV2 创建用户gRPC服务 v2
+func NewUserGrpcServiceV2(appService *application.UserAppService) *UserGrpcServiceV2 {
+	return &UserGrpcServiceV2{
+		appService: appService,
+	}
+}
+
+// GetUser 根据ID获取用户
+func (s *UserGrpcServiceV2) GetUser(ctx context.Context, req *userv2pb.GetUserRequest) (*userv2pb.User, error) {
+	user, err := s.appService.GetUserById(ctx, req.Id)
+	if err != nil {
+		return nil, toStatusError(err, req.Id)
+	}
+	return toProtoUserV2(user), nil
+}
+
+// GetUserByUsername 根据用户名获取用户
+func (s *UserGrpcServiceV2) GetUserByUsername(ctx context.Context, req *userv2pb.GetUserByUsernameRequest) (*userv2pb.User, error) {
+	user, err := s.appService.GetUserByUsername(ctx, req.Username)
+	if err != nil {
+		return nil, toStatusError(err, req.Username)
+	}
+	return toProtoUserV2(user), nil
+}
+
+// GetUserByEmail 根据邮箱获取用户
+func (s *UserGrpcServiceV2) GetUserByEmail(ctx context.Context, req *userv2pb.GetUserByEmailRequest) (*userv2pb.User, error) {
+	user, err := s.appService.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, toStatusError(err, req.Email)
+	}
+	return toProtoUserV2(user), nil
+}
+
+// CreateUser 创建用户
+func (s *UserGrpcServiceV2) CreateUser(ctx context.Context, req *userv2pb.CreateUserRequest) (*userv2pb.User, error) {
+	user, err := s.appService.CreateUser(ctx, req.Username, req.Email, req.Password)
+	if err != nil {
+		return nil, toStatusError(err, req.Username)
+	}
+	return toProtoUserV2(user), nil
+}
+
+// UpdateUser 按字段掩码部分更新用户
+func (s *UserGrpcServiceV2) UpdateUser(ctx context.Context, req *userv2pb.UpdateUserRequest) (*userv2pb.User, error) {
+	if req.User.GetId() == "" {
+		return nil, invalidArgument("user.id", "is required")
+	}
+	fields, violations := userUpdateFields(req.User.Username, req.User.Email, req.UpdateMask.GetPaths())
+	if len(violations) > 0 {
+		return nil, fieldViolations(violations...)
+	}
+
+	user, err := s.appService.UpdateUser(ctx, commands.UpdateUserCommand{
+		UserID:   req.User.Id,
+		Username: req.User.Username,
+		Email:    req.User.Email,
+		Fields:   fields,
+	})
+	if err != nil {
+		return nil, toStatusError(err, req.User.Id)
+	}
+	return toProtoUserV2(user), nil
+}
+
+// UpdateUserPassword 更新用户密码，返回更新后的用户
+func (s *UserGrpcServiceV2) UpdateUserPassword(ctx context.Context, req *userv2pb.UpdateUserPasswordRequest) (*userv2pb.User, error) {
+	if err := s.appService.UpdateUserPassword(ctx, req.Id, req.CurrentPassword, req.NewPassword); err != nil {
+		return nil, toStatusError(err, req.Id)
+	}
+
+	user, err := s.appService.GetUserById(ctx, req.Id)
+	if err != nil {
+		return nil, toStatusError(err, req.Id)
+	}
+	return toProtoUserV2(user), nil
+}
+
+// ListUsers 分页列出用户
+func (s *UserGrpcServiceV2) ListUsers(ctx context.Context, req *userv2pb.ListUsersRequest) (*userv2pb.ListUsersResponse, error) {
+	filter := domain.UserListFilter{
+		EmailDomain:    req.EmailDomain,
+		UsernamePrefix: req.UsernamePrefix,
+	}
+	if req.CreatedAfter != nil {
+		if err := req.CreatedAfter.CheckValid(); err != nil {
+			return nil, invalidArgument("created_after", "must be a valid timestamp")
+		}
+		filter.CreatedAfter = req.CreatedAfter.AsTime()
+	}
+	if req.CreatedBefore != nil {
+		if err := req.CreatedBefore.CheckValid(); err != nil {
+			return nil, invalidArgument("created_before", "must be a valid timestamp")
+		}
+		filter.CreatedBefore = req.CreatedBefore.AsTime()
+	}
+
+	result, err := s.appService.ListUsers(ctx, queries.ListUsersQuery{
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+		OrderBy:   req.OrderBy,
+		Filter:    filter,
+	})
+	if err != nil {
+		return nil, toStatusError(err, "")
+	}
+
+	users := make([]*userv2pb.User, 0, len(result.Users))
+	for _, u := range result.Users {
+		users = append(users, toProtoUserV2(u))
+	}
+	return &userv2pb.ListUsersResponse{
+		Users:         users,
+		NextPageToken: result.NextPageToken,
+	}, nil
+}
+
+// WatchUsers 推送用户变更事件，支持按序号续传
+func (s *UserGrpcServiceV2) WatchUsers(req *userv2pb.WatchUsersRequest, stream userv2pb.UserService_WatchUsersServer) error {
+	err := s.appService.WatchUsers(stream.Context(), req.AfterSequence, func(event domain.UserEvent) error {
+		return stream.Send(toProtoUserEventV2(event))
+	})
+	return toStatusError(err, "")
+}
+
+// DeleteUser 软删除用户
+func (s *UserGrpcServiceV2) DeleteUser(ctx context.Context, req *userv2pb.DeleteUserRequest) (*emptypb.Empty, error) {
+	if err := s.appService.DeleteUser(ctx, req.Id); err != nil {
+		return nil, toStatusError(err, req.Id)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// RestoreUser 恢复已软删除的用户
+func (s *UserGrpcServiceV2) RestoreUser(ctx context.Context, req *userv2pb.RestoreUserRequest) (*userv2pb.User, error) {
+	user, err := s.appService.RestoreUser(ctx, req.Id)
+	if err != nil {
+		return nil, toStatusError(err, req.Id)
+	}
+	return toProtoUserV2(user), nil
+}
+
+// PurgeUser 彻底清除用户（管理接口）
+func (s *UserGrpcServiceV2) PurgeUser(ctx context.Context, req *userv2pb.PurgeUserRequest) (*emptypb.Empty, error) {
+	if err := s.appService.PurgeUser(ctx, req.Id); err != nil {
+		return nil, toStatusError(err, req.Id)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// toProtoUserV2 将领域用户转换为 v2 protobuf 用户
+func toProtoUserV2(u *domain.User) *userv2pb.User {
+	if u == nil {
+		return nil
+	}
+	return &userv2pb.User{
+		Id:        u.ID,
+		Username:  u.Username,
+		Email:     u.Email,
+		CreatedAt: timestamppb.New(u.CreatedAt),
+		UpdatedAt: timestamppb.New(u.UpdatedAt),
+	}
+}
+
+// toProtoUserEventV2 将领域事件转换为 v2 protobuf 事件
+func toProtoUserEventV2(e domain.UserEvent) *userv2pb.UserEvent {
+	var eventType userv2pb.UserEvent_Type
+	switch e.Type {
+	case domain.UserCreated:
+		eventType = userv2pb.UserEvent_TYPE_CREATED
+	case domain.UserUpdated:
+		eventType = userv2pb.UserEvent_TYPE_UPDATED
+	case domain.UserDeleted:
+		eventType = userv2pb.UserEvent_TYPE_DELETED
+	}
+	return &userv2pb.UserEvent{
+		Sequence:      e.Sequence,
+		Type:          eventType,
+		User:          toProtoUserV2(&e.User),
+		ChangedFields: e.ChangedFields,
+		OccurredAt:    timestamppb.New(e.OccurredAt),
+	}
+}
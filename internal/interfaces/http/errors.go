@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"go-protos/internal/domain"
+	"go-protos/pkg/requestid"
+)
+
+// errorResponse 统一的JSON错误响应体
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code      int    `json:"code"`            // HTTP状态码
+	Reason    string `json:"reason"`          // 稳定的错误原因码，与gRPC ErrorInfo.reason一致
+	Message   string `json:"message"`         // 可读的错误信息
+	Field     string `json:"field,omitempty"` // 关联字段，校验类错误使用
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// writeError 将应用层返回的错误转换为HTTP状态码和JSON错误体
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		// 客户端已断开，无需响应
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		writeErrorBody(w, r, http.StatusGatewayTimeout, "DEADLINE_EXCEEDED", "request timed out", "")
+		return
+	}
+
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		// 未知错误不向客户端暴露内部细节
+		writeErrorBody(w, r, http.StatusInternalServerError, "INTERNAL", "internal error", "")
+		return
+	}
+
+	var code int
+	switch domainErr.Kind {
+	case domain.KindNotFound:
+		code = http.StatusNotFound
+	case domain.KindAlreadyExists:
+		code = http.StatusConflict
+	case domain.KindInvalidArgument, domain.KindOutOfRange:
+		code = http.StatusBadRequest
+	case domain.KindUnauthenticated:
+		code = http.StatusUnauthorized
+	case domain.KindPermissionDenied:
+		code = http.StatusForbidden
+	default:
+		writeErrorBody(w, r, http.StatusInternalServerError, domainErr.Reason, "internal error", "")
+		return
+	}
+	writeErrorBody(w, r, code, domainErr.Reason, domainErr.Error(), domainErr.Field)
+}
+
+// writeInvalidArgument 输出接口层参数校验失败的错误
+func writeInvalidArgument(w http.ResponseWriter, r *http.Request, field, message string) {
+	writeErrorBody(w, r, http.StatusBadRequest, "INVALID_ARGUMENT", message, field)
+}
+
+// writeErrorBody 输出JSON错误体
+func writeErrorBody(w http.ResponseWriter, r *http.Request, code int, reason, message, field string) {
+	writeJSON(w, code, errorResponse{Error: errorBody{
+		Code:      code,
+		Reason:    reason,
+		Message:   message,
+		Field:     field,
+		RequestID: requestid.FromContext(r.Context()),
+	}})
+}
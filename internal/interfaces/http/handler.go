@@ -0,0 +1,192 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go-protos/internal/application"
+	"go-protos/internal/domain"
+)
+
+// maxRequestBodySize 请求体大小上限
+const maxRequestBodySize = 1 << 20
+
+// UserHandler 用户REST API处理器
+type UserHandler struct {
+	appService *application.UserAppService
+}
+
+// NewUserHandler 创建用户REST API处理器
+func NewUserHandler(appService *application.UserAppService) *UserHandler {
+	return &UserHandler{
+		appService: appService,
+	}
+}
+
+// Register 注册用户相关路由
+func (h *UserHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/users", h.createUser)
+	mux.HandleFunc("GET /api/users/{id}", h.getUserById)
+	mux.HandleFunc("POST /api/users/by-username", h.getUserByUsername)
+	mux.HandleFunc("POST /api/users/by-email", h.getUserByEmail)
+	mux.HandleFunc("PATCH /api/users/{id}/email", h.updateUserEmail)
+	mux.HandleFunc("PATCH /api/users/{id}/password", h.updateUserPassword)
+}
+
+// userResponse 用户JSON表示（不含密码哈希）
+type userResponse struct {
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// createUserRequest 创建用户请求体
+type createUserRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// getUserByUsernameRequest 按用户名查询请求体，用户名放在请求体中，避免出现在访问日志、代理日志和链路追踪的URL中
+type getUserByUsernameRequest struct {
+	Username string `json:"username"`
+}
+
+// getUserByEmailRequest 按邮箱查询请求体，邮箱放在请求体中，避免出现在访问日志和代理日志的URL中
+type getUserByEmailRequest struct {
+	Email string `json:"email"`
+}
+
+// updateUserEmailRequest 更新邮箱请求体
+type updateUserEmailRequest struct {
+	Email string `json:"email"`
+}
+
+// updateUserPasswordRequest 更新密码请求体
+type updateUserPasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// createUser POST /api/users
+func (h *UserHandler) createUser(w http.ResponseWriter, r *http.Request) {
+	var req createUserRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user, err := h.appService.CreateUser(r.Context(), req.Username, req.Email, req.Password)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/users/"+user.ID)
+	writeJSON(w, http.StatusCreated, toUserResponse(user))
+}
+
+// getUserById GET /api/users/{id}
+func (h *UserHandler) getUserById(w http.ResponseWriter, r *http.Request) {
+	user, err := h.appService.GetUserById(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserResponse(user))
+}
+
+// getUserByUsername POST /api/users/by-username
+func (h *UserHandler) getUserByUsername(w http.ResponseWriter, r *http.Request) {
+	var req getUserByUsernameRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user, err := h.appService.GetUserByUsername(r.Context(), req.Username)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserResponse(user))
+}
+
+// getUserByEmail POST /api/users/by-email
+func (h *UserHandler) getUserByEmail(w http.ResponseWriter, r *http.Request) {
+	var req getUserByEmailRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user, err := h.appService.GetUserByEmail(r.Context(), req.Email)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserResponse(user))
+}
+
+// updateUserEmail PATCH /api/users/{id}/email
+func (h *UserHandler) updateUserEmail(w http.ResponseWriter, r *http.Request) {
+	var req updateUserEmailRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.appService.UpdateUserEmail(r.Context(), r.PathValue("id"), req.Email); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// updateUserPassword PATCH /api/users/{id}/password
+func (h *UserHandler) updateUserPassword(w http.ResponseWriter, r *http.Request) {
+	var req updateUserPasswordRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if err := h.appService.UpdateUserPassword(r.Context(), r.PathValue("id"), req.CurrentPassword, req.NewPassword); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeJSON 解析JSON请求体，失败时输出400错误并返回false
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeErrorBody(w, r, http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE", "request body too large", "")
+			return false
+		}
+		writeInvalidArgument(w, r, "body", "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// toUserResponse 将领域用户转换为JSON表示
+func toUserResponse(u *domain.User) userResponse {
+	return userResponse{
+		ID:        u.ID,
+		Username:  u.Username,
+		Email:     u.Email,
+		CreatedAt: u.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: u.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,261 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go-protos/internal/application"
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/eventbus"
+	"go-protos/internal/infrastructure/metrics"
+	"go-protos/internal/infrastructure/persistence/inmem"
+	"go-protos/internal/infrastructure/ratelimit"
+	"go-protos/internal/infrastructure/security/auth"
+	"go-protos/internal/infrastructure/security/password"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// testAPIKey 测试使用的服务账号API密钥，拥有全部权限
+const testAPIKey = "test-api-key"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	hasher, err := password.New(password.Config{Algorithm: password.AlgorithmBcrypt, BcryptCost: 4})
+	require.NoError(t, err)
+	repo := inmem.NewInMemoryUserRepository()
+	appService := application.NewUserAppService(repo, repo.Roles(), domain.NewUserDomainService(repo, domain.ReuseAfterPurge), hasher, eventbus.NewUserEventLog(0))
+	scopes := make([]string, 0, len(domain.AllPermissions))
+	for _, perm := range domain.AllPermissions {
+		scopes = append(scopes, string(perm))
+	}
+	authenticator, err := auth.NewAuthenticator(nil, []auth.APIKey{{Name: "test-service", SHA256: auth.HashAPIKey(testAPIKey), Scopes: scopes}})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer((&Server{appService: appService, authenticator: authenticator, authPolicy: auth.NewPolicy(true, nil, nil)}).setupRoutes())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func doJSON(t *testing.T, method, url, body string) (*http.Response, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-Api-Key", testAPIKey)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out map[string]any
+	if resp.StatusCode != http.StatusNoContent {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	}
+	return resp, out
+}
+
+func TestUserHandler(t *testing.T) {
+	srv := newTestServer(t)
+
+	resp, body := doJSON(t, http.MethodPost, srv.URL+"/api/users", `{"username":"alice","email":"alice@example.com","password":"correct-horse"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	id := body["id"].(string)
+	assert.Equal(t, "/api/users/"+id, resp.Header.Get("Location"))
+	assert.NotContains(t, body, "password_hash")
+	assert.NotEmpty(t, resp.Header.Get("x-request-id"))
+
+	resp, body = doJSON(t, http.MethodPost, srv.URL+"/api/users/by-email", `{"email":"alice@example.com"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, id, body["id"])
+
+	resp, body = doJSON(t, http.MethodPost, srv.URL+"/api/users/by-username", `{"username":"alice"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, id, body["id"])
+
+	resp, _ = doJSON(t, http.MethodPatch, srv.URL+"/api/users/"+id+"/email", `{"email":"alice2@example.com"}`)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, body = doJSON(t, http.MethodGet, srv.URL+"/api/users/"+id, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "alice2@example.com", body["email"])
+
+	resp, _ = doJSON(t, http.MethodPatch, srv.URL+"/api/users/"+id+"/password", `{"current_password":"correct-horse","new_password":"battery-staple"}`)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestUserHandler_Errors(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		reason string
+	}{
+		{"not found", http.MethodGet, "/api/users/missing", "", http.StatusNotFound, "USER_NOT_FOUND"},
+		{"username not found", http.MethodPost, "/api/users/by-username", `{"username":"missing"}`, http.StatusNotFound, "USER_NOT_FOUND"},
+		{"invalid json", http.MethodPost, "/api/users", `{"username":`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"unknown field", http.MethodPost, "/api/users", `{"password_hash":"x"}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{"weak password", http.MethodPost, "/api/users", `{"username":"bob","password":"short"}`, http.StatusBadRequest, "WEAK_PASSWORD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := doJSON(t, tt.method, srv.URL+tt.path, tt.body)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			errBody := body["error"].(map[string]any)
+			assert.Equal(t, tt.reason, errBody["reason"])
+			assert.Equal(t, float64(tt.status), errBody["code"])
+			assert.NotEmpty(t, errBody["request_id"])
+		})
+	}
+
+	// 重复用户名
+	resp, _ := doJSON(t, http.MethodPost, srv.URL+"/api/users", `{"username":"bob","password":"correct-horse"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	resp, body := doJSON(t, http.MethodPost, srv.URL+"/api/users", `{"username":"bob","password":"correct-horse"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Equal(t, "username", body["error"].(map[string]any)["field"])
+
+	// 公开路由上的匿名请求由应用层拒绝
+	anonymous, err := http.Get(srv.URL + "/api/users/missing")
+	require.NoError(t, err)
+	anonymous.Body.Close()
+	assert.Equal(t, http.StatusForbidden, anonymous.StatusCode)
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator(nil, []auth.APIKey{{Name: "svc", SHA256: auth.HashAPIKey("secret-key")}})
+	require.NoError(t, err)
+	handler := authMiddleware(authenticator)(requireAuthMiddleware(auth.NewPolicy(false, DefaultPublicRoutes, nil))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := domain.PrincipalFromContext(r.Context())
+		if ok {
+			w.Header().Set("X-Principal", principal.ID)
+		}
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/u-1", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "MISSING_CREDENTIALS")
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/users/u-1", nil)
+	req.Header.Set("X-Api-Key", "secret-key")
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "svc", rec.Header().Get("X-Principal"))
+}
+
+func TestSpanRouteMiddleware_RedactsPath(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previousProvider) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	handler := tracingMiddleware()(spanRouteMiddleware(mux))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users/alice@example.com", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	for _, attr := range spans[0].Attributes() {
+		assert.NotContains(t, attr.Value.Emit(), "alice@example.com", string(attr.Key))
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), []ratelimit.Rule{
+		{Match: "POST /api/users", KeyBy: ratelimit.KeyByIP, Limit: ratelimit.Limit{Rate: 0.5, Burst: 1}},
+	}, nil)
+	require.NoError(t, err)
+	handler := rateLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/users", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.Contains(t, rec.Body.String(), "RATE_LIMITED")
+
+	// 其他路由不受该规则限制
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/u-1", nil))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestRateLimit_InvalidCredentials(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), nil,
+		&ratelimit.Rule{Match: "*", KeyBy: ratelimit.KeyByPrincipal, Limit: ratelimit.Limit{Rate: 0.1, Burst: 3}})
+	require.NoError(t, err)
+	authenticator, err := auth.NewAuthenticator(nil, []auth.APIKey{{Name: "svc", SHA256: auth.HashAPIKey("secret-key")}})
+	require.NoError(t, err)
+	s := &Server{authenticator: authenticator, authPolicy: auth.NewPolicy(false, DefaultPublicRoutes, nil), rateLimiter: limiter}
+	srv := httptest.NewServer(s.setupRoutes())
+	t.Cleanup(srv.Close)
+
+	// 猜测API密钥的请求在被拒绝前按IP消耗令牌
+	codes := make([]int, 0, 4)
+	for i := 0; i < 4; i++ {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/users/u-1", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Api-Key", "guess-"+strconv.Itoa(i))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		codes = append(codes, resp.StatusCode)
+	}
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+}
+
+func TestMetricsRoute(t *testing.T) {
+	m := metrics.New()
+	authenticator, err := auth.NewAuthenticator(nil, nil)
+	require.NoError(t, err)
+	s := &Server{
+		authenticator: authenticator,
+		authPolicy:    auth.NewPolicy(false, []string{"/health", "/metrics"}, nil),
+		metrics:       m,
+		metricsPath:   "/metrics",
+	}
+	srv := httptest.NewServer(s.setupRoutes())
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	// 未认证请求在路由之前被拒绝，记为 unmatched
+	resp, err = http.Get(srv.URL + "/api/users/u-1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `http_server_requests_total{method="GET",route="GET /health",status="200"} 1`)
+	assert.Contains(t, string(body), `http_server_requests_total{method="GET",route="unmatched",status="401"} 1`)
+}
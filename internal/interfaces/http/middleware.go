@@ -0,0 +1,242 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-protos/internal/domain"
+	"go-protos/internal/infrastructure/logging"
+	"go-protos/internal/infrastructure/metrics"
+	"go-protos/internal/infrastructure/ratelimit"
+	"go-protos/internal/infrastructure/security/auth"
+	"go-protos/internal/infrastructure/security/tlsconfig"
+	"go-protos/pkg/redact"
+	"go-protos/pkg/requestid"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultPublicRoutes 默认无需认证的路径，可在配置的 protected_routes 中列出以覆盖
+var DefaultPublicRoutes = []string{"/health"}
+
+// statusRecorder 记录响应状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// requestIDMiddleware 读取或生成请求ID，写入上下文并通过响应头返回
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestid.Sanitize(r.Header.Get(requestid.MetadataKey))
+		w.Header().Set(requestid.MetadataKey, id)
+		// 请求ID中间件位于访问日志之前，同时建立请求级日志作用域
+		ctx := logging.WithRequestScope(requestid.NewContext(r.Context(), id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// accessLogMiddleware 记录每个请求的方法、路由、耗时和状态码
+func accessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", r.Pattern),
+			slog.Int("status", rec.status),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("peer", r.RemoteAddr),
+		}
+		slog.LogAttrs(r.Context(), level, "http access", attrs...)
+	})
+}
+
+// tracingMiddleware 为每个请求创建服务端 span，并从请求头提取 W3C Trace Context 作为父 span；
+// 使用全局 TracerProvider，未启用链路追踪时为空操作。untraced 中的路径（健康检查、指标）不创建 span
+func tracingMiddleware(untraced ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return otelhttp.NewHandler(next, "http.server",
+			otelhttp.WithFilter(func(r *http.Request) bool {
+				return !slices.Contains(untraced, r.URL.Path)
+			}),
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return r.Method
+			}),
+		)
+	}
+}
+
+// spanRouteMiddleware 路由匹配后以 "方法 路由模式" 重命名服务端 span 并记录 http.route，
+// 需位于认证中间件之外以读取回写的路由模式；otelhttp 记录的 url.path 可能夹带邮箱等个人信息，替换为清理后的路径
+func spanRouteMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(semconv.URLPath(redact.String(r.URL.Path)))
+		next.ServeHTTP(w, r)
+		if r.Pattern == "" {
+			return
+		}
+		_, route, ok := strings.Cut(r.Pattern, " ")
+		if !ok {
+			route = r.Pattern
+		}
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRouteKey.String(route))
+	})
+}
+
+// metricsMiddleware 按方法、路由模式和状态码记录请求次数与耗时；m 为空时不采集。
+// 未匹配任何路由（包括在路由之前被拒绝）的请求记为 unmatched，避免按原始路径产生无界标签
+func metricsMiddleware(m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if m == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			route := r.Pattern
+			if route == "" {
+				route = "unmatched"
+			}
+			m.ObserveHTTP(r.Method, route, rec.status, time.Since(start))
+		})
+	}
+}
+
+// recoveryMiddleware 捕获处理器panic并返回500
+func recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				slog.ErrorContext(r.Context(), "http handler panic",
+					slog.String("path", r.URL.Path),
+					slog.Any("panic", v),
+					slog.String("stack", string(debug.Stack())),
+				)
+				writeErrorBody(w, r, http.StatusInternalServerError, "INTERNAL", "internal error", "")
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authMiddleware 认证中间件：解析 Bearer 访问令牌或API密钥，认证通过后调用方通过 domain.PrincipalFromContext
+// 传递给处理器和应用层；凭证缺失或无效时记录原因，由 requireAuthMiddleware 在限流之后拒绝受保护的路径
+func authMiddleware(authenticator *auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var authed *http.Request
+			if principal, err := requestPrincipal(authenticator, r); err != nil {
+				authed = r.WithContext(context.WithValue(r.Context(), authFailureKey{}, err))
+			} else {
+				// 让外层访问日志也能记录调用方
+				logging.SetPrincipal(r.Context(), principal)
+				authed = r.WithContext(domain.ContextWithPrincipal(r.Context(), principal))
+			}
+			next.ServeHTTP(w, authed)
+			// 路由匹配发生在副本上，回写给外层访问日志使用
+			r.Pattern = authed.Pattern
+		})
+	}
+}
+
+// authFailureKey 上下文中凭证解析失败的原因
+type authFailureKey struct{}
+
+// requireAuthMiddleware 受保护的路由没有有效凭证时返回 401，需位于限流中间件之后
+// 公开路由的凭证是可选的，缺失或无效时按匿名请求处理
+func requireAuthMiddleware(policy *auth.Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := domain.PrincipalFromContext(r.Context()); ok || policy.IsPublic(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			err, _ := r.Context().Value(authFailureKey{}).(error)
+			if err == nil {
+				err = domain.ErrMissingCredentials
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="go-protos"`)
+			writeError(w, r, err)
+		})
+	}
+}
+
+// requestPrincipal 从 Authorization 或 X-Api-Key 请求头解析调用方
+func requestPrincipal(authenticator *auth.Authenticator, r *http.Request) (*domain.Principal, error) {
+	switch {
+	case r.Header.Get("Authorization") != "":
+		scheme, credentials, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "bearer") || credentials == "" {
+			return nil, domain.ErrInvalidAccessToken
+		}
+		return authenticator.AuthenticateBearer(credentials)
+	case r.Header.Get("X-Api-Key") != "":
+		return authenticator.AuthenticateAPIKey(r.Header.Get("X-Api-Key"))
+	default:
+		return nil, domain.ErrMissingCredentials
+	}
+}
+
+// clientIdentityMiddleware 将通过校验的客户端证书身份写入上下文，
+// 处理器通过 tlsconfig.ClientIdentityFromContext 读取
+func clientIdentityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if identity := tlsconfig.IdentityFromConnectionState(r.TLS); identity != nil {
+			r = r.WithContext(tlsconfig.ContextWithClientIdentity(r.Context(), identity))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitMiddleware 限流中间件，需位于凭证解析之后、认证检查之前，凭证无效的请求按IP消耗令牌；limiter 为空时不限流
+func rateLimitMiddleware(limiter *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			caller := ratelimit.Caller{IP: r.RemoteAddr}
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				caller.IP = host
+			}
+			caller.Principal, _ = domain.PrincipalFromContext(r.Context())
+
+			decision := limiter.Allow(r.Context(), r.Method, r.URL.Path, caller)
+			if !decision.Allowed {
+				retrySeconds := int64((decision.RetryAfter + time.Second - 1) / time.Second)
+				w.Header().Set("Retry-After", strconv.FormatInt(retrySeconds, 10))
+				writeErrorBody(w, r, http.StatusTooManyRequests, "RATE_LIMITED", "rate limit exceeded", "")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
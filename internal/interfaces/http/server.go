@@ -2,49 +2,92 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 
 	"go-protos/config"
 	"go-protos/internal/application"
+	"go-protos/internal/infrastructure/metrics"
+	"go-protos/internal/infrastructure/ratelimit"
+	"go-protos/internal/infrastructure/security/auth"
 )
 
 // Server HTTP服务器
 type Server struct {
-	appService *application.UserAppService
-	config     *config.AppConfig
-	server     *http.Server
+	appService    *application.UserAppService
+	config        *config.AppConfig
+	authenticator *auth.Authenticator
+	authPolicy    *auth.Policy
+	rateLimiter   *ratelimit.Limiter
+	tlsConfig     *tls.Config
+	metrics       *metrics.Metrics
+	metricsPath   string
+	server        *http.Server
 }
 
-// NewServer 创建HTTP服务器
-func NewServer(appService *application.UserAppService, cfg *config.AppConfig) *Server {
-	return &Server{
-		appService: appService,
-		config:     cfg,
-	}
-}
-
-// Start 启动HTTP服务器
-func (s *Server) Start() error {
-	readTimeout, err := s.config.GetReadTimeout()
+// NewServer 创建HTTP服务器，tlsConfig 为空时使用明文连接；
+// m 不为空时在 metricsPath 上暴露 Prometheus 指标
+// 底层 http.Server 在此创建，Start 和 Stop 可以在不同 goroutine 中调用
+func NewServer(
+	appService *application.UserAppService,
+	cfg *config.AppConfig,
+	authenticator *auth.Authenticator,
+	authPolicy *auth.Policy,
+	rateLimiter *ratelimit.Limiter,
+	tlsConfig *tls.Config,
+	m *metrics.Metrics,
+	metricsPath string,
+) (*Server, error) {
+	readTimeout, err := cfg.GetReadTimeout()
 	if err != nil {
-		return fmt.Errorf("failed to parse read timeout: %w", err)
+		return nil, fmt.Errorf("failed to parse read timeout: %w", err)
 	}
 
-	writeTimeout, err := s.config.GetWriteTimeout()
+	writeTimeout, err := cfg.GetWriteTimeout()
 	if err != nil {
-		return fmt.Errorf("failed to parse write timeout: %w", err)
+		return nil, fmt.Errorf("failed to parse write timeout: %w", err)
 	}
 
+	s := &Server{
+		appService:    appService,
+		config:        cfg,
+		authenticator: authenticator,
+		authPolicy:    authPolicy,
+		rateLimiter:   rateLimiter,
+		tlsConfig:     tlsConfig,
+		metrics:       m,
+		metricsPath:   metricsPath,
+	}
 	s.server = &http.Server{
-		Addr:         s.config.GetAddress(),
+		Addr:         cfg.GetAddress(),
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 		Handler:      s.setupRoutes(),
+		TLSConfig:    tlsConfig,
+		// 连接级错误（如 TLS 握手失败）同样输出到结构化日志
+		ErrorLog: slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
 	}
+	return s, nil
+}
 
-	fmt.Printf("HTTP server starting on %s\n", s.config.GetAddress())
-	return s.server.ListenAndServe()
+// Start 启动HTTP服务器，阻塞直到服务器停止；Stop 先于 Start 调用时直接返回
+func (s *Server) Start() error {
+	slog.Info("http server starting", slog.String("addr", s.server.Addr), slog.Bool("tls", s.tlsConfig != nil))
+	var err error
+	if s.tlsConfig != nil {
+		// 证书由 TLSConfig 提供，支持不重启轮换
+		err = s.server.ListenAndServeTLS("", "")
+	} else {
+		err = s.server.ListenAndServe()
+	}
+	// Stop 触发的关闭属于正常退出
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 // Stop 停止HTTP服务器
@@ -53,16 +96,27 @@ func (s *Server) Stop(ctx context.Context) error {
 }
 
 // setupRoutes 设置路由
-func (s *Server) setupRoutes() *http.ServeMux {
+func (s *Server) setupRoutes() http.Handler {
 	mux := http.NewServeMux()
 
 	// 健康检查
-	mux.HandleFunc("/health", s.healthCheck)
+	mux.HandleFunc("GET /health", s.healthCheck)
+
+	// Prometheus 指标
+	if s.metrics != nil {
+		mux.Handle("GET "+s.metricsPath, s.metrics.Handler())
+	}
 
 	// API路由
-	mux.HandleFunc("/api/users/", s.handleUsers)
+	NewUserHandler(s.appService).Register(mux)
 
-	return mux
+	// 中间件顺序：链路追踪 -> 请求ID -> 客户端证书身份 -> 访问日志 -> 指标 -> 路由 span 命名 -> panic恢复 -> 解析凭证 -> 限流 -> 拒绝未认证请求
+	traced := tracingMiddleware("/health", s.metricsPath)
+	authenticate := authMiddleware(s.authenticator)
+	rateLimit := rateLimitMiddleware(s.rateLimiter)
+	requireAuth := requireAuthMiddleware(s.authPolicy)
+	observe := metricsMiddleware(s.metrics)
+	return traced(requestIDMiddleware(clientIdentityMiddleware(accessLogMiddleware(observe(spanRouteMiddleware(recoveryMiddleware(authenticate(rateLimit(requireAuth(mux))))))))))
 }
 
 // healthCheck 健康检查
@@ -70,10 +124,3 @@ func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
-
-// handleUsers 处理用户相关请求
-func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
-	// 实现HTTP API
-	w.WriteHeader(http.StatusNotImplemented)
-	w.Write([]byte("Not implemented"))
-}
@@ -0,0 +1,34 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-protos/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_StopRightAfterStart(t *testing.T) {
+	s, err := NewServer(nil, &config.AppConfig{Host: "127.0.0.1", Port: 0, ReadTimeout: "1s", WriteTimeout: "1s"}, nil, nil, nil, nil, nil, "")
+	require.NoError(t, err)
+
+	// Start 和 Stop 在不同 goroutine 中几乎同时调用，Start 应正常返回
+	done := make(chan error, 1)
+	go func() { done <- s.Start() }()
+	require.NoError(t, s.Stop(context.Background()))
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return after Stop")
+	}
+}
+
+func TestNewServer_InvalidTimeout(t *testing.T) {
+	_, err := NewServer(nil, &config.AppConfig{ReadTimeout: "soon"}, nil, nil, nil, nil, nil, "")
+	assert.ErrorContains(t, err, "failed to parse read timeout")
+}
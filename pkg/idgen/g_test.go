@@ -1,4 +1,4 @@
-// package idgen
+package idgen_test
 
 // import (
 // 	"fmt"
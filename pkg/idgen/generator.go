@@ -65,12 +65,31 @@ type IDGenerator struct {
 	mu       sync.Mutex
 	lastTime int64
 	sequence uint64
+
+	nowMilli   func() int64             // 当前毫秒时间戳，测试中可替换
+	onOverflow func(wait time.Duration) // 序列号溢出等待回调
+}
+
+// Option ID 生成器选项
+type Option func(*IDGenerator)
+
+// WithOverflowObserver 同一毫秒内序列号用尽、需要等待下一毫秒时回调，参数为等待时长，用于监控
+func WithOverflowObserver(fn func(wait time.Duration)) Option {
+	return func(g *IDGenerator) {
+		g.onOverflow = fn
+	}
 }
 
 // NewIDGenerator 创建一个 ID 生成器实例（简单实现，不包含 nodeID）
 // 若需要多节点安全（节点区分），可扩展此构造函数接受 nodeID 并把 nodeID 放入高位或专用位。
-func NewIDGenerator() *IDGenerator {
-	return &IDGenerator{}
+func NewIDGenerator(opts ...Option) *IDGenerator {
+	g := &IDGenerator{
+		nowMilli: func() int64 { return time.Now().UTC().UnixMilli() },
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
 // Generate 生成安全 ID（将 BizType 放在高位）
@@ -79,7 +98,7 @@ func (g *IDGenerator) Generate(biz BizType) (uint64, error) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	now := time.Now().UTC().UnixMilli()
+	now := g.nowMilli()
 	timePart := now - epoch
 
 	if timePart < 0 {
@@ -97,10 +116,14 @@ func (g *IDGenerator) Generate(biz BizType) (uint64, error) {
 		g.sequence = (g.sequence + 1) & sequenceMask
 		if g.sequence == 0 {
 			// 序列溢出，等待到下一个毫秒
+			waitStart := time.Now()
 			for now <= g.lastTime {
-				now = time.Now().UTC().UnixMilli()
+				now = g.nowMilli()
 			}
 			timePart = now - epoch
+			if g.onOverflow != nil {
+				g.onOverflow(time.Since(waitStart))
+			}
 		}
 	} else {
 		g.sequence = 0
@@ -0,0 +1,43 @@
+package idgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDGenerator_SequenceOverflowWait(t *testing.T) {
+	var waits int
+	gen := NewIDGenerator(WithOverflowObserver(func(time.Duration) { waits++ }))
+
+	// 固定时钟让同一毫秒内的序列号用尽，之后的等待循环中时钟前进一毫秒
+	now := int64(epoch + 1000)
+	calls, advanceAfter := 0, int(sequenceMask)+2
+	gen.nowMilli = func() int64 {
+		calls++
+		if calls > advanceAfter {
+			return now + 1
+		}
+		return now
+	}
+
+	seen := make(map[uint64]bool)
+	for i := 0; i <= int(sequenceMask); i++ {
+		id, err := gen.Generate(UserType)
+		require.NoError(t, err)
+		seen[id] = true
+	}
+	assert.Zero(t, waits)
+
+	id, err := gen.Generate(UserType)
+	require.NoError(t, err)
+	assert.Equal(t, 1, waits)
+	assert.False(t, seen[id])
+
+	parsed, biz, seq := ParseID(id)
+	assert.Equal(t, now+1, parsed.UnixMilli())
+	assert.Equal(t, UserType, biz)
+	assert.Zero(t, seq)
+}
@@ -0,0 +1,160 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout 默认的停止排空时限
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Component 由管理器统一启停的组件（服务器、后台任务等）
+type Component struct {
+	Name string
+	// Start 阻塞运行直到组件停止；停止时 ctx 会被取消。
+	// 在停止前返回（无论是否出错）都会触发整体停止
+	Start func(ctx context.Context) error
+	// Stop 在时限内排空并停止组件，可为空（仅依赖 ctx 取消即可停止的组件）
+	Stop func(ctx context.Context) error
+}
+
+// closer 所有组件停止后需要释放的资源
+type closer struct {
+	name  string
+	close func() error
+}
+
+// Manager 生命周期管理器：一起启动所有组件，收到信号或任一组件退出时在时限内统一停止
+type Manager struct {
+	components      []Component
+	closers         []closer
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+}
+
+// New 创建生命周期管理器，shutdownTimeout 为零时使用默认值
+func New(shutdownTimeout time.Duration) *Manager {
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultShutdownTimeout
+	}
+	return &Manager{
+		shutdownTimeout: shutdownTimeout,
+		signals:         []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+	}
+}
+
+// Add 注册组件
+func (m *Manager) Add(c Component) {
+	m.components = append(m.components, c)
+}
+
+// AddCloser 注册在所有组件停止后释放的资源（如数据库连接池），按注册的逆序关闭
+func (m *Manager) AddCloser(name string, close func() error) {
+	m.closers = append(m.closers, closer{name: name, close: close})
+}
+
+// result 组件运行结果
+type result struct {
+	name string
+	err  error
+}
+
+// Run 启动所有组件并阻塞，直到收到 SIGINT/SIGTERM、ctx 结束或任一组件退出，然后执行停止流程
+// 任一组件启动或运行失败、停止出错或超出时限时返回非空错误
+func (m *Manager) Run(ctx context.Context) error {
+	ctx, stopSignals := signal.NotifyContext(ctx, m.signals...)
+	defer stopSignals()
+
+	// 组件上下文只在停止阶段取消，与信号解耦
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	defer cancelRun()
+
+	results := make(chan result, len(m.components))
+	for _, c := range m.components {
+		slog.Info("starting component", slog.String("component", c.Name))
+		go func(c Component) {
+			results <- result{name: c.Name, err: c.Start(runCtx)}
+		}(c)
+	}
+
+	var errs []error
+	running := len(m.components)
+	select {
+	case <-ctx.Done():
+		slog.Info("shutdown signal received")
+	case r := <-results:
+		running--
+		if r.err != nil {
+			slog.Error("component failed", slog.String("component", r.name), slog.Any("error", r.err))
+			errs = append(errs, fmt.Errorf("%s: %w", r.name, r.err))
+		} else {
+			slog.Warn("component exited unexpectedly", slog.String("component", r.name))
+		}
+	}
+
+	errs = append(errs, m.shutdown(cancelRun, results, running)...)
+	return errors.Join(errs...)
+}
+
+// shutdown 在时限内并发停止所有组件，等待其退出后释放资源
+func (m *Manager) shutdown(cancelRun context.CancelFunc, results <-chan result, running int) []error {
+	slog.Info("shutting down", slog.Duration("timeout", m.shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		errs []error
+		wg   sync.WaitGroup
+	)
+	cancelRun()
+	for _, c := range m.components {
+		if c.Stop == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(c Component) {
+			defer wg.Done()
+			if err := c.Stop(shutdownCtx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("stop %s: %w", c.Name, err))
+				mu.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	// 等待剩余组件退出
+	for running > 0 {
+		select {
+		case r := <-results:
+			running--
+			if r.err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", r.name, r.err))
+			}
+		case <-shutdownCtx.Done():
+			errs = append(errs, fmt.Errorf("shutdown timed out with %d component(s) still running", running))
+			running = 0
+		}
+	}
+
+	// 所有组件停止后按逆序释放资源
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		c := m.closers[i]
+		if err := c.close(); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", c.name, err))
+		}
+	}
+
+	if len(errs) == 0 {
+		slog.Info("shutdown completed")
+	}
+	return errs
+}
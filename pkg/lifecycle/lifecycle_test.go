@@ -0,0 +1,82 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingComponent 运行直到 Stop 被调用
+func blockingComponent(name string, stopped *[]string) Component {
+	done := make(chan struct{})
+	return Component{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			<-done
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			*stopped = append(*stopped, name)
+			close(done)
+			return nil
+		},
+	}
+}
+
+func TestManager_StopsOnContextCancel(t *testing.T) {
+	var stopped []string
+	var closed []string
+
+	m := New(time.Second)
+	m.Add(blockingComponent("grpc", &stopped))
+	m.Add(Component{
+		Name: "worker",
+		Start: func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		},
+	})
+	m.AddCloser("first", func() error { closed = append(closed, "first"); return nil })
+	m.AddCloser("second", func() error { closed = append(closed, "second"); return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	require.NoError(t, m.Run(ctx))
+	assert.Equal(t, []string{"grpc"}, stopped)
+	assert.Equal(t, []string{"second", "first"}, closed)
+}
+
+func TestManager_StartFailure(t *testing.T) {
+	var stopped []string
+	listenErr := errors.New("address already in use")
+
+	m := New(time.Second)
+	m.Add(blockingComponent("grpc", &stopped))
+	m.Add(Component{
+		Name:  "http",
+		Start: func(ctx context.Context) error { return listenErr },
+	})
+
+	err := m.Run(context.Background())
+	assert.ErrorIs(t, err, listenErr)
+	assert.Equal(t, []string{"grpc"}, stopped)
+}
+
+func TestManager_ShutdownTimeout(t *testing.T) {
+	m := New(20 * time.Millisecond)
+	m.Add(Component{
+		Name: "stuck",
+		Start: func(ctx context.Context) error {
+			select {}
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorContains(t, m.Run(ctx), "timed out")
+}
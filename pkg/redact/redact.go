@@ -0,0 +1,112 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Class 字段的数据分类，决定输出到日志、链路和错误信息时的处理方式
+type Class int
+
+const (
+	// Public 非敏感字段，只清理其中夹带的邮箱和令牌
+	Public Class = iota
+	// Email 邮箱，保留首字符和域名
+	Email
+	// Username 用户名，只保留首字符
+	Username
+	// Secret 密码、密码哈希、令牌、密钥等，整体替换
+	Secret
+)
+
+// Placeholder 敏感内容被整体替换后的占位符
+const Placeholder = "[REDACTED]"
+
+// fields 字段分类表，键为规范化后的字段名（小写，"-" 替换为 "_"）
+// 新增包含个人信息或凭证的字段时在这里登记
+var fields = map[string]Class{
+	"email":             Email,
+	"new_email":         Email,
+	"username":          Username,
+	"username_or_email": Email,
+	"password":          Secret,
+	"current_password":  Secret,
+	"new_password":      Secret,
+	"password_hash":     Secret,
+	"token":             Secret,
+	"access_token":      Secret,
+	"refresh_token":     Secret,
+	"page_token":        Secret,
+	"api_key":           Secret,
+	"x_api_key":         Secret,
+	"authorization":     Secret,
+	"secret":            Secret,
+	"cookie":            Secret,
+}
+
+// Classify 返回字段的数据分类，未登记的字段为 Public
+func Classify(field string) Class {
+	key := strings.ReplaceAll(strings.ToLower(field), "-", "_")
+	return fields[key]
+}
+
+// Field 按字段分类处理字段值
+func Field(field, value string) string {
+	return Value(Classify(field), value)
+}
+
+// Value 按数据分类处理值
+func Value(class Class, value string) string {
+	switch class {
+	case Email:
+		if !emailPattern.MatchString(value) {
+			// 格式不合法的邮箱（或用户名、邮箱二选一的字段中填写的用户名）同样不能原样输出
+			return maskUsername(value)
+		}
+		return maskEmails(value)
+	case Username:
+		return maskUsername(value)
+	case Secret:
+		if value == "" {
+			return ""
+		}
+		return Placeholder
+	default:
+		return String(value)
+	}
+}
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	jwtPattern    = regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[^\s,;"']+`)
+)
+
+// String 清理自由文本（如日志消息、数据库驱动返回的错误）中夹带的邮箱、JWT 和 Bearer 凭证
+func String(s string) string {
+	if s == "" {
+		return s
+	}
+	s = bearerPattern.ReplaceAllString(s, "${1}"+Placeholder)
+	s = jwtPattern.ReplaceAllString(s, Placeholder)
+	return maskEmails(s)
+}
+
+// maskEmails 将文本中的邮箱替换为 "a***@example.com"
+func maskEmails(s string) string {
+	return emailPattern.ReplaceAllStringFunc(s, func(email string) string {
+		at := strings.LastIndex(email, "@")
+		return maskUsername(email[:at]) + email[at:]
+	})
+}
+
+// maskUsername 只保留首字符，隐藏长度
+func maskUsername(s string) string {
+	if s == "" {
+		return ""
+	}
+	for _, r := range s {
+		return string(r) + "***"
+	}
+	return s
+}
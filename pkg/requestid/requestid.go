@@ -0,0 +1,44 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// MetadataKey 请求ID在gRPC元数据 / HTTP头中的键
+const MetadataKey = "x-request-id"
+
+// maxLength 客户端传入请求ID的最大长度，超出则重新生成
+const maxLength = 128
+
+type contextKey struct{}
+
+// New 生成新的请求ID
+func New() string {
+	return uuid.New().String()
+}
+
+// Sanitize 校验客户端传入的请求ID，不合法时返回新生成的ID
+func Sanitize(id string) string {
+	if id == "" || len(id) > maxLength {
+		return New()
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return New()
+		}
+	}
+	return id
+}
+
+// NewContext 将请求ID写入上下文
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext 从上下文读取请求ID，不存在时返回空字符串
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-protos/pkg/redact"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName 本服务创建 span 使用的 Tracer 名称
+const InstrumentationName = "go-protos"
+
+// Start 使用全局 TracerProvider 创建子 span，未启用链路追踪时为空操作
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(InstrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// expectedError 属于正常结果的错误，如查询未找到记录，Expected 返回 true 时不记为 span 错误
+type expectedError interface {
+	Expected() bool
+}
+
+// End 结束 span，*errp 不为空且不是预期错误时记录错误并将状态置为 Error
+// 配合命名返回值在 defer 中使用：defer tracing.End(span, &err)
+func End(span trace.Span, errp *error) {
+	if errp != nil && *errp != nil && !isExpected(*errp) {
+		RecordError(span, *errp)
+	}
+	span.End()
+}
+
+// isExpected 判断错误链中是否有预期错误
+func isExpected(err error) bool {
+	var expected expectedError
+	return errors.As(err, &expected) && expected.Expected()
+}
+
+// RecordError 记录错误事件并将状态置为 Error，错误信息中夹带的邮箱和令牌会被脱敏
+func RecordError(span trace.Span, err error) {
+	message := redact.String(err.Error())
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(
+		semconv.ExceptionType(fmt.Sprintf("%T", err)),
+		semconv.ExceptionMessage(message),
+	))
+	span.SetStatus(codes.Error, message)
+}
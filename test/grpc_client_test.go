@@ -2,18 +2,48 @@ package cmd_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"log"
+	"os"
 	"testing"
 	"time"
 
 	userpb "go-protos/proto/userpb"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 )
 
+// transportCredentials 根据环境变量选择连接方式：
+// GRPC_CA_FILE 启用 TLS，再配置 GRPC_CLIENT_CERT、GRPC_CLIENT_KEY 时使用 mTLS，都未配置时使用明文
+func transportCredentials() credentials.TransportCredentials {
+	caFile := os.Getenv("GRPC_CA_FILE")
+	if caFile == "" {
+		return insecure.NewCredentials()
+	}
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		log.Fatalf("failed to read CA file: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caPEM)
+	cfg := &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	if certFile, keyFile := os.Getenv("GRPC_CLIENT_CERT"), os.Getenv("GRPC_CLIENT_KEY"); certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			log.Fatalf("failed to load client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(cfg)
+}
+
 func TestGrpcClient(t *testing.T) {
 	// 1. 建立 gRPC 连接
-	conn, err := grpc.Dial("localhost:9090", grpc.WithInsecure())
+	conn, err := grpc.NewClient("localhost:9090", grpc.WithTransportCredentials(transportCredentials()))
 	if err != nil {
 		log.Fatalf("failed to connect: %v", err)
 	}
@@ -25,11 +55,13 @@ func TestGrpcClient(t *testing.T) {
 	// 3. 调用 CreateUser
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
+	// 受保护的方法需要服务账号API密钥
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", os.Getenv("GRPC_API_KEY"))
 
 	createResp, err := client.CreateUser(ctx, &userpb.CreateUserRequest{
-		Username:     "alice",
-		Email:        "alice@example.com",
-		PasswordHash: "hashedpassword123",
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: "password123",
 	})
 	if err != nil {
 		log.Fatalf("CreateUser error: %v", err)